@@ -37,21 +37,98 @@ func main() {
 
 	log.Print("Start\r\n")
 
+	// 設定ファイルの読み込み
+	confLoad()
+
+	// 履歴の照会
+	if *rirekiSyoukai != "" {
+		inDir, _ := filepath.Split(flag.Arg(0))
+		rirekiCreate(*rirekiSyoukai, inDir)
+		log.Print("Finish !\r\n")
+		return
+	}
+
+	// 出力の比較
+	if *hikakuMode {
+		if flag.NArg() != 2 {
+			log.Print("比較する2つのファイルを指定してください（NwToToyota.exe -hikaku 前のファイル 後のファイル）\r\n")
+			log.Print("Finish !\r\n")
+			return
+		}
+		hikakuCreate(flag.Arg(0), flag.Arg(1))
+		log.Print("Finish !\r\n")
+		return
+	}
+
+	// 人事名簿との受診状況の照合
+	if *syougouNendo != "" {
+		syougouCreate(*syougouNendo, flag.Args())
+		log.Print("Finish !\r\n")
+		return
+	}
+
+	// 定期健康診断結果報告（様式第6号）の集計
+	if *houkokuKikan != "" {
+		houkokuCreate(*houkokuKikan, flag.Args())
+		log.Print("Finish !\r\n")
+		return
+	}
+
 	// ファイルを読み込んで二次元配列に入れる
 	records := readfile(flag.Arg(0))
+	if len(records) == 0 {
+		log.Print("入力ファイルにデータがありません\r\n")
+		log.Print("Finish !\r\n")
+		return
+	}
+	inHeadSet(records[0])
 
 	// 出力する会社を調査
 	coRecods := coSurvey(records)
 
-	//出力するフォルダを作成
-	outDir := dirCreate(flag.Arg(0))
+	// 社内用の一覧は入力ファイルと同じ場所に作成する
+	inDir, _ := filepath.Split(flag.Arg(0))
+
+	// 出力の検証に使う入力ファイルの値（社員番号を補う・そろえる前に写しておく）
+	kensyouSet(records)
+
+	// 人事名簿で社員番号を補う
+	jinjiSet(inDir, records, coRecods)
+
+	// 社員番号の確認
+	syainCheck(records)
+
+	//出力するフォルダを作成（健保組合へ提出するデータは別のフォルダにする）
+	outDir := dirCreate(flag.Arg(0), "トヨタモビリティ東京")
+	kenpoDir := dirCreate(flag.Arg(0), "健保組合提出用")
 
 	// データの変換
-	dataConversion(outDir, records, coRecods)
+	dataConversion(outDir, kenpoDir, records, coRecods)
 
 	// 受診者名簿の作成
 	meiboCreate(outDir, records, coRecods)
 
+	// 提供除外者一覧（社内用）の作成
+	jogaiCreate(inDir, records)
+
+	// 要確認者一覧（社内用）の作成
+	youkakuninCreate(inDir, records, coRecods)
+
+	// 辞書に登録のない記載
+	mitourokuPrint()
+
+	// 保険証記号のない受診者
+	hokenPrint()
+
+	// 実行の記録
+	rirekiRun(flag.Arg(0), outDir)
+
+	// 出力の検証（誤りがあればここで止める）
+	kensyouRun()
+
+	// 送付の記録（-sabunのときだけ）
+	souhuRun()
+
 	log.Print("Finish !\r\n")
 
 }
@@ -81,18 +158,57 @@ func readfile(filename string) [][]string {
 	return readrecords
 }
 
+// 入力ファイルの項目名と列番号（A85パターンに後から追加した項目は項目名で探す）
+var inHead = map[string]int{}
+
+func inHeadSet(head []string) {
+	for i, h := range head {
+		inHead[string(norm.NFKC.Bytes([]byte(strings.TrimSpace(h))))] = i
+	}
+}
+
+func inVal(inRec []string, names ...string) string {
+	for _, name := range names {
+		if i, ok := inHead[string(norm.NFKC.Bytes([]byte(name)))]; ok && i < len(inRec) {
+			return strings.TrimSpace(inRec[i])
+		}
+	}
+	return ""
+}
+
+func inHas(names ...string) bool {
+	for _, name := range names {
+		if _, ok := inHead[string(norm.NFKC.Bytes([]byte(name)))]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// 会社マスタ（所属cd, 会社名, 組合コード, 保険者番号）
+var kaisya = [][]string{{"2000100100000001", "トヨタモビリティ東京（株）", "", ""},
+	{"2000100100000026", "ティーシーサービス（株）", "", ""},
+	{"9500100100000001", "トヨタモビリティ東京（株）", "", ""},
+	{"2000100100000020", "ＴＭプロサービス（株）", "", ""},
+	{"2000100100000008", "（株）トヨテック", "", ""},
+	{"2000100100009002", "トヨタ東京カローラ（株）", "", ""},
+	{"2000100100009004", "（株）センチュリーサービス", "", ""},
+}
+
+// 出力する会社（所属cd, 会社名, 件数, 組合コード, 保険者番号）
 func coSurvey(records [][]string) [][]string {
-	companys := [][]string{{"2000100100000001", "トヨタモビリティ東京（株）", "0"},
-		{"2000100100000026", "ティーシーサービス（株）", "0"},
-		{"9500100100000001", "トヨタモビリティ東京（株）", "0"},
-		{"2000100100000020", "ＴＭプロサービス（株）", "0"},
-		{"2000100100000008", "（株）トヨテック", "0"},
-		{"2000100100009002", "トヨタ東京カローラ（株）", "0"},
-		{"2000100100009004", "（株）センチュリーサービス", "0"},
+	companys := make([][]string, 0)
+	for _, k := range kaisya {
+		com := append([]string{k[0], k[1], "0"}, k[2:]...)
+		companys = append(companys, com)
 	}
 
+	// 提供除外の方だけの会社は出力しない
 	coRecMax := len(records)
 	for i := 1; i < coRecMax; i++ {
+		if jogaiCheck(records[i]) != "" {
+			continue
+		}
 		for _, com := range companys {
 			if com[0] == records[i][4] {
 				count, _ := strconv.Atoi(com[2])
@@ -110,16 +226,22 @@ func coSurvey(records [][]string) [][]string {
 		}
 	}
 
+	// 組合コード・保険者番号の確認
+	for _, com := range outCompanys {
+		hokensyaCheck(com)
+	}
+
 	return outCompanys
 
 }
 
-func dirCreate(path string) string {
+// 入力ファイルと同じ場所に「name[日付]」のフォルダを作る（同じ日に作ったフォルダがあればそれを使う）
+func dirCreate(path string, name string) string {
 	day := time.Now()
 	outDir, _ := filepath.Split(path)
-	outDirPlus := outDir + "/トヨタモビリティ東京" + day.Format("20060102")
+	outDirPlus := filepath.Join(outDir, name+day.Format("20060102"))
 
-	if err := os.Mkdir(outDirPlus, 0777); err != nil {
+	if err := os.MkdirAll(outDirPlus, 0777); err != nil {
 		log.Print(outDirPlus + "\r\n")
 		log.Print("出力先のディレクトリを作成できませんでした\r\n")
 		return outDir
@@ -128,1078 +250,1019 @@ func dirCreate(path string) string {
 	}
 }
 
-func dataConversion(filename string, inRecs [][]string, coRecs [][]string) {
-	// var excelFile *xlsx.File
-	// var sheet *xlsx.Sheet
-	var vcell *xlsx.Cell
-	var r int
-	var cell string
-
+// 健診データのタイトル行（3行）
+func headRows() [][]string {
 	recLen := 222 //出力するレコードの項目数
+	head := make([][]string, 0)
+
+	// 1行目（タイトル）
 	cRec := make([]string, recLen)
-	var I int
 
+	cRec[0] = "idou.sya_bg"
+	cRec[19] = "knk_kenkork.jushin_date"
+	cRec[22] = "knk_kenkork_kensa.kensa_val_071"
+	cRec[27] = "knk_kenkork_kensa.kensa_val_005"
+	cRec[28] = "knk_kenkork_kensa.kensa_val_006"
+	cRec[29] = "knk_kenkork_kensa.kensa_val_007"
+	cRec[31] = "knk_kenkork_kensa.kensa_val_008"
+	cRec[33] = "knk_kenkork_kensa.kensa_val_001"
+	cRec[34] = "knk_kenkork_kensa.kensa_val_002"
+	cRec[35] = "knk_kenkork_kensa.kensa_val_003"
+	cRec[36] = "knk_kenkork_kensa.kensa_val_023"
+	cRec[37] = "knk_kenkork_kensa.kensa_val_027"
+	cRec[38] = "knk_kenkork_kensa.kensa_val_025"
+	cRec[39] = "knk_kenkork_kensa.kensa_val_024"
+	cRec[40] = "knk_kenkork_kensa.kensa_val_028"
+	cRec[41] = "knk_kenkork_kensa.kensa_val_026"
+	cRec[44] = "knk_kenkork_kensa.kensa_val_039"
+	cRec[45] = "knk_kenkork_kensa.kensa_val_038"
+	cRec[46] = "knk_kenkork_kensa.kensa_val_037"
+	cRec[48] = "knk_kenkork_kensa.kensa_val_033"
+	cRec[49] = "knk_kenkork_kensa.kensa_val_034"
+	cRec[50] = "knk_kenkork_kensa.kensa_val_035"
+	cRec[51] = "knk_kenkork_Kensa.kensa_val_080"
+	cRec[54] = "knk_kenkork_kensa.kensa_val_041"
+	cRec[55] = "knk_kenkork_kensa.kensa_val_079"
+	cRec[57] = "knk_kenkork_kensa.kensa_val_042"
+	cRec[64] = "knk_kenkork_kensa.kensa_val_031"
+	cRec[65] = "knk_kenkork_kensa.kensa_val_030"
+	cRec[70] = "knk_kenkork_kensa.kensa_val_047"
+	cRec[72] = "knk_kenkork_kensa.kensa_val_021"
+	cRec[78] = "knk_kenkork_kensa.kensa_val_010"
+	cRec[79] = "knk_kenkork_kensa.kensa_val_011"
+	cRec[80] = "knk_kenkork_kensa.kensa_val_012"
+	cRec[81] = "knk_kenkork_kensa.kensa_val_013"
+	cRec[168] = "knk_kenkork_kensa.kensa_val_072"
+	cRec[176] = "knk_kenkork_kensa.kensa_val_049"
+	cRec[179] = "knk_kenkork_kensa.kensa_val_050"
+	cRec[182] = "knk_kenkork_kensa.kensa_val_051"
+	cRec[185] = "knk_kenkork_kensa.kensa_val_052"
+	cRec[186] = "knk_kenkork_kensa.kensa_val_053"
+	cRec[187] = "knk_kenkork_kensa.kensa_val_054"
+	cRec[188] = "knk_kenkork_kensa.kensa_val_055"
+	cRec[189] = "knk_kenkork_kensa.kensa_val_056"
+	cRec[190] = "knk_kenkork_kensa.kensa_val_057"
+	cRec[191] = "knk_kenkork_kensa.kensa_val_058"
+	cRec[192] = "knk_kenkork_kensa.kensa_val_059"
+	cRec[193] = "knk_kenkork_kensa.kensa_val_060"
+	cRec[194] = "knk_kenkork_kensa.kensa_val_061"
+	cRec[195] = "knk_kenkork_kensa.kensa_val_081"
+	cRec[196] = "knk_kenkork_kensa.kensa_val_062"
+	cRec[197] = "knk_kenkork_kensa.kensa_val_063"
+	cRec[198] = "knk_kenkork_kensa.kensa_val_064"
+	cRec[199] = "knk_kenkork_kensa.kensa_val_082"
+	cRec[200] = "knk_kenkork_kensa.kensa_val_065"
+	cRec[201] = "knk_kenkork_kensa.kensa_val_066"
+	cRec[202] = "knk_kenkork_kensa.kensa_val_067"
+	cRec[203] = "knk_kenkork_kensa.kensa_val_068"
+	cRec[204] = "knk_kenkork_kensa.kensa_val_069"
+	cRec[205] = "knk_kenkork_kensa.kensa_val_070"
+	cRec[212] = "knk_kenkork_kensa.kensa_val_020"
+	cRec[213] = "knk_kenkork_kensa.hantei_val_020"
+	cRec[214] = "knk_kenkork_kensa.kensa_val_044"
+	cRec[215] = "knk_kenkork_kensa.kensa_val_045"
+	cRec[216] = "knk_kenkork_kensa.kensa_val_016"
+	cRec[217] = "knk_kenkork_kensa.kensa_val_017"
+	cRec[218] = "knk_kenkork_kensa.kensa_val_018"
+	cRec[219] = "knk_kenkork_kensa.kensa_val_019"
+	cRec[220] = "knk_kenkork_kensa.kensa_val_046"
+	cRec[221] = "knk_kenkork_kensa.hantei_val_046"
+	head = append(head, cRec)
+
+	// 2行目（タイトル）
+	cRec = make([]string, recLen)
+
+	cRec[0] = "#社員番号"
+	cRec[19] = "受診日付"
+	cRec[22] = "検査コード071_医療機関側判定結果"
+	cRec[27] = "検査コード005_医療機関側検査値"
+	cRec[28] = "検査コード006_医療機関側検査値"
+	cRec[29] = "検査コード007_医療機関側検査値"
+	cRec[31] = "検査コード008_医療機関側検査値"
+	cRec[33] = "検査コード001_医療機関側検査値"
+	cRec[34] = "検査コード02_医療機関側判定結果"
+	cRec[35] = "検査コード003_医療機関側検査値"
+	cRec[36] = "検査コード023_医療機関側検査値"
+	cRec[37] = "検査コード027_医療機関側検査値"
+	cRec[38] = "検査コード025_医療機関側検査値"
+	cRec[39] = "検査コード024_医療機関側検査値"
+	cRec[40] = "検査コード028_医療機関側検査値"
+	cRec[41] = "検査コード026_医療機関側検査値"
+	cRec[44] = "検査コード039_医療機関側検査値"
+	cRec[45] = "検査コード038_医療機関側検査値"
+	cRec[46] = "検査コード037_医療機関側検査値"
+	cRec[48] = "検査コード033_医療機関側検査値"
+	cRec[49] = "検査コード034_医療機関側検査値"
+	cRec[50] = "検査コード035_医療機関側検査値"
+	cRec[51] = "検査コード080_医療機関側検査値"
+	cRec[54] = "検査コード041_医療機関側検査値"
+	cRec[55] = "検査コード079_医療機関側検査値"
+	cRec[57] = "検査コード042_医療機関側検査値"
+	cRec[64] = "検査コード031_医療機関側検査値"
+	cRec[65] = "検査コード030_医療機関側判定結果"
+	cRec[70] = "検査コード047_医療機関側検査値"
+	cRec[72] = "検査コード021_医療機関側検査値"
+	cRec[78] = "検査コード010_医療機関側検査値"
+	cRec[79] = "検査コード011_医療機関側検査値"
+	cRec[80] = "検査コード012_医療機関側検査値"
+	cRec[81] = "検査コード013_医療機関側検査値"
+	cRec[168] = "検査コード072_医療機関側検査値"
+	cRec[176] = "検査コード049_医療機関側検査値"
+	cRec[179] = "検査コード050_医療機関側検査値"
+	cRec[182] = "検査コード051_医療機関側検査値"
+	cRec[185] = "検査コード052_医療機関側検査値"
+	cRec[186] = "検査コード053_医療機関側検査値"
+	cRec[187] = "検査コード054_医療機関側検査値"
+	cRec[188] = "検査コード055_医療機関側検査値"
+	cRec[189] = "検査コード056_医療機関側検査値"
+	cRec[190] = "検査コード057_医療機関側検査値"
+	cRec[191] = "検査コード058_医療機関側検査値"
+	cRec[192] = "検査コード059_医療機関側検査値"
+	cRec[193] = "検査コード060_医療機関側検査値"
+	cRec[194] = "検査コード061_医療機関側検査値"
+	cRec[195] = "検査コード081_医療機関側検査値"
+	cRec[196] = "検査コード062_医療機関側検査値"
+	cRec[197] = "検査コード063_医療機関側検査値"
+	cRec[198] = "検査コード064_医療機関側検査値"
+	cRec[199] = "検査コード082_医療機関側検査値"
+	cRec[200] = "検査コード065_医療機関側検査値"
+	cRec[201] = "検査コード066_医療機関側検査値"
+	cRec[202] = "検査コード067_医療機関側検査値"
+	cRec[203] = "検査コード068_医療機関側検査値"
+	cRec[204] = "検査コード069_医療機関側検査値"
+	cRec[205] = "検査コード070_医療機関側検査値"
+	cRec[212] = "検査コード020_医療機関側検査値"
+	cRec[213] = "検査コード020_医療機関側検査値"
+	cRec[214] = "検査コード044_医療機関側検査値"
+	cRec[215] = "検査コード045_医療機関側検査値"
+	cRec[216] = "検査コード016_医療機関側検査値"
+	cRec[217] = "検査コード017_医療機関側検査値"
+	cRec[218] = "検査コード018_医療機関側検査値"
+	cRec[219] = "検査コード019_医療機関側検査値"
+	cRec[220] = "検査コード046_医療機関側検査値"
+	cRec[221] = "検査コード046_医療機関側検査値"
+	head = append(head, cRec)
+
+	// 3行目（タイトル）
+	cRec = make([]string, recLen)
+
+	cRec[0] = "#従業員番号"
+	cRec[1] = "組合コード"
+	cRec[2] = "受診者ID"
+	cRec[3] = "保険証記号"
+	cRec[4] = "保険証番号"
+	cRec[5] = "続柄"
+	cRec[6] = "枝番"
+	cRec[7] = "所属コード"
+	cRec[8] = "所属名称"
+	cRec[9] = "社員番号"
+	cRec[10] = "加入番号"
+	cRec[11] = "扶養番号"
+	cRec[12] = "受診者区分"
+	cRec[13] = "性別"
+	cRec[14] = "氏名漢字"
+	cRec[15] = "氏名カナ"
+	cRec[16] = "生年月日"
+	cRec[17] = "実施年度"
+	cRec[18] = "年齢"
+	cRec[19] = "受診日"
+	cRec[20] = "健診区分"
+	cRec[21] = "医療機関コード"
+	cRec[22] = "医療機関名称"
+	cRec[23] = "機関コード"
+	cRec[24] = "機関名称"
+	cRec[25] = "機関住所"
+	cRec[26] = "受付NO"
+	cRec[27] = "身長"
+	cRec[28] = "体重"
+	cRec[29] = "BMI"
+	cRec[30] = "内臓脂肪面積"
+	cRec[31] = "腹囲"
+	cRec[32] = "業務歴"
+	cRec[33] = "既往歴"
+	cRec[34] = "自覚症状"
+	cRec[35] = "他覚症状"
+	cRec[36] = "収縮期血圧(その他)"
+	cRec[37] = "収縮期血圧(２回目)"
+	cRec[38] = "収縮期血圧(１回目)"
+	cRec[39] = "拡張期血圧(その他)"
+	cRec[40] = "拡張期血圧(２回目)"
+	cRec[41] = "拡張期血圧(１回目)"
+	cRec[42] = "採血時間"
+	cRec[43] = "総コレステロール"
+	cRec[44] = "中性脂肪"
+	cRec[45] = "HDLコレステロール"
+	cRec[46] = "LDLコレステロール"
+	cRec[47] = "NON-HDLコレステロール"
+	cRec[48] = "GOT(AST)"
+	cRec[49] = "GPT(ALT)"
+	cRec[50] = "γ-GT(γ-GTP)"
+	cRec[51] = "血清クレアチニン"
+	cRec[52] = "eGFR"
+	cRec[53] = "血清尿酸"
+	cRec[54] = "空腹時血糖"
+	cRec[55] = "随時血糖"
+	cRec[56] = "HbA1c"
+	cRec[57] = "HbA1c(NGSP)"
+	cRec[58] = "尿糖"
+	cRec[59] = "尿蛋白"
+	cRec[60] = "尿潜血"
+	cRec[61] = "尿素窒素"
+	cRec[62] = "尿ウロビリノーゲン"
+	cRec[63] = "ヘマトクリット値"
+	cRec[64] = "血色素量(ヘモグロビン値)"
+	cRec[65] = "赤血球数"
+	cRec[66] = "貧血検査実施理由"
+	cRec[67] = "白血球数"
+	cRec[68] = "血小板数"
+	cRec[69] = "血清アミラーゼ"
+	cRec[70] = "心電図(所見)"
+	cRec[71] = "心電図(実施理由)"
+	cRec[72] = "胸部X線検査(所見)"
+	cRec[73] = "胸部X線検査(撮影年月日)"
+	cRec[74] = "喀痰検査(塗抹鏡検 一般細菌)(所見)"
+	cRec[75] = "喀痰検査(塗抹鏡検 抗酸菌)"
+	cRec[76] = "喀痰検査(ガフキー号数)"
+	cRec[77] = "便潜血"
+	cRec[78] = "視力(裸眼右)"
+	cRec[79] = "視力(矯正右)"
+	cRec[80] = "視力(裸眼左)"
+	cRec[81] = "視力(矯正左)"
+	cRec[82] = "聴力(右1000Hz)"
+	cRec[83] = "聴力(右4000Hz)"
+	cRec[84] = "聴力(左1000Hz)"
+	cRec[85] = "聴力(左4000Hz)"
+	cRec[86] = "聴力(その他の所見)"
+	cRec[87] = "眼底検査(キースワグナー分類)"
+	cRec[88] = "眼底検査(シェイエ分類:H)"
+	cRec[89] = "眼底検査(シェイエ分類:S)"
+	cRec[90] = "眼底検査(SCOTT分類)"
+	cRec[91] = "眼底検査（wong-Mitchell分類）"
+	cRec[92] = "眼底検査（改変Davis分類）"
+	cRec[93] = "眼底検査(その他の所見)"
+	cRec[94] = "眼底検査(実施理由)"
+	cRec[95] = "その他の法定特殊健康診断"
+	cRec[96] = "その他の法定検査"
+	cRec[97] = "その他の検査"
+	cRec[98] = "追加項目1"
+	cRec[99] = "追加項目2"
+	cRec[100] = "追加項目3"
+	cRec[101] = "追加項目4"
+	cRec[102] = "追加項目5"
+	cRec[103] = "追加項目6"
+	cRec[104] = "追加項目7"
+	cRec[105] = "追加項目8"
+	cRec[106] = "追加項目9"
+	cRec[107] = "追加項目10"
+	cRec[108] = "BMI判定"
+	cRec[109] = "内臓脂肪面積判定"
+	cRec[110] = "腹囲判定"
+	cRec[111] = "血圧判定"
+	cRec[112] = "総コレステロール判定"
+	cRec[113] = "中性脂肪判定"
+	cRec[114] = "HDLコレステロール判定"
+	cRec[115] = "LDLコレステロール判定"
+	cRec[116] = "NON-HDLコレステロール判定"
+	cRec[117] = "GOT(AST)判定"
+	cRec[118] = "GPT(ALT)判定"
+	cRec[119] = "γ-GT(γ-GTP)判定"
+	cRec[120] = "血清クレアチニン判定"
+	cRec[121] = "eGFR判定"
+	cRec[122] = "血清尿酸判定"
+	cRec[123] = "空腹時血糖判定"
+	cRec[124] = "随時血糖判定"
+	cRec[125] = "HbA1c判定"
+	cRec[126] = "HbA1c（NGSP)判定"
+	cRec[127] = "尿糖判定"
+	cRec[128] = "尿蛋白判定"
+	cRec[129] = "尿潜血判定"
+	cRec[130] = "尿素窒素判定"
+	cRec[131] = "尿ウロビリノーゲン判定"
+	cRec[132] = "ヘマトクリット値判定"
+	cRec[133] = "血色素量(ヘモグロビン値)判定"
+	cRec[134] = "赤血球数判定"
+	cRec[135] = "白血球数判定"
+	cRec[136] = "血小板数判定"
+	cRec[137] = "視力(右)判定"
+	cRec[138] = "視力(左)判定"
+	cRec[139] = "追加項目判定1"
+	cRec[140] = "追加項目判定2"
+	cRec[141] = "追加項目判定3"
+	cRec[142] = "追加項目判定4"
+	cRec[143] = "追加項目判定5"
+	cRec[144] = "追加項目判定6"
+	cRec[145] = "追加項目判定7"
+	cRec[146] = "追加項目判定8"
+	cRec[147] = "追加項目判定9"
+	cRec[148] = "追加項目判定10"
+	cRec[149] = "コメント"
+	cRec[150] = "総合判定"
+	cRec[151] = "受診勧奨区分"
+	cRec[152] = "指導状態"
+	cRec[153] = "再検査区分"
+	cRec[154] = "一次健診日"
+	cRec[155] = "結果通知区分"
+	cRec[156] = "メタボリック判定(血圧リスク)"
+	cRec[157] = "メタボリック判定(血糖リスク)"
+	cRec[158] = "メタボリック判定(脂質リスク)"
+	cRec[159] = "メタボリック判定(リスクカウント)"
+	cRec[160] = "支援レベル(血圧リスク)"
+	cRec[161] = "支援レベル(血糖リスク)"
+	cRec[162] = "支援レベル(脂質リスク)"
+	cRec[163] = "支援レベル(喫煙リスク)"
+	cRec[164] = "支援レベル(リスクカウント)"
+	cRec[165] = "メタボリックシンドローム判定"
+	cRec[166] = "支援レベル"
+	cRec[167] = "医師の診断(判定)"
+	cRec[168] = "健康診断を実施した医師の氏名"
+	cRec[169] = "医師の意見"
+	cRec[170] = "意見を述べた医師の氏名"
+	cRec[171] = "歯科医師による健康診断"
+	cRec[172] = "歯科医師による健康診断を実施した歯科医師の氏名"
+	cRec[173] = "歯科医師の意見"
+	cRec[174] = "意見を述べた歯科医師の氏名"
+	cRec[175] = "備考"
+	cRec[176] = "服薬１_血圧"
+	cRec[177] = "血圧_薬剤"
+	cRec[178] = "血圧_服薬理由"
+	cRec[179] = "服薬２_血糖"
+	cRec[180] = "血糖_薬剤"
+	cRec[181] = "血糖_服薬理由"
+	cRec[182] = "服薬３_脂質"
+	cRec[183] = "脂質_薬剤"
+	cRec[184] = "脂質_服薬理由"
+	cRec[185] = "既往歴１_脳血管"
+	cRec[186] = "既往歴２_心血管"
+	cRec[187] = "既往歴３_腎不全人工透析"
+	cRec[188] = "貧血"
+	cRec[189] = "喫煙"
+	cRec[190] = "２０歳からの体重変化"
+	cRec[191] = "３０分以上の運動習慣"
+	cRec[192] = "歩行又は身体活動"
+	cRec[193] = "歩行速度"
+	cRec[194] = "１年間の体重変化"
+	cRec[195] = "食事についての咀嚼"
+	cRec[196] = "食べ方１_早食い等"
+	cRec[197] = "食べ方２_就寝前"
+	cRec[198] = "食べ方３_夜食間食"
+	cRec[199] = "食べ方３_三食以外の間食"
+	cRec[200] = "食習慣"
+	cRec[201] = "飲酒"
+	cRec[202] = "飲酒量"
+	cRec[203] = "睡眠"
+	cRec[204] = "生活習慣の改善"
+	cRec[205] = "保健指導の希望"
+	cRec[206] = "報告対象区分"
+	cRec[207] = "保健指導からの除外"
+	cRec[208] = "取込年月日"
+	cRec[209] = "胸部X線判定①"
+	cRec[210] = "胸部X線判定②"
+	cRec[211] = "心電図判定"
+	cRec[212] = "胸部レントゲン検査"
+	cRec[213] = "胸部レントゲン判定"
+	cRec[214] = "尿糖"
+	cRec[215] = "尿蛋白"
+	cRec[216] = "聴力(右1000Hz)"
+	cRec[217] = "聴力(右4000Hz)"
+	cRec[218] = "聴力(左1000Hz)"
+	cRec[219] = "聴力(左4000Hz)"
+	cRec[220] = "心電図検査"
+	cRec[221] = "心電図判定"
+	head = append(head, cRec)
+
+	return head
+}
+
+func dataConversion(filename string, kenpoDir string, inRecs [][]string, coRecs [][]string) {
+	head := headRows()
 	day := time.Now()
 
 	//会社毎に健診データファイルを作成する
 	for _, coRec := range coRecs {
+		jigyosya := make([][]string, 0) // 事業者へ提出する本人のデータ
+		kenpo := make([][]string, 0)    // 健保組合へ提出する家族と特定健診対象者のデータ
+		rireki := make([][]string, 0)   // 履歴に残すデータ（提供除外・要確認の方も含む）
 
-		/*
-			outfile, err := os.Create(filename + coRec[1] + "健診データ" + day.Format("20060102") + ".txt")
-			failOnError(err)
-			defer outfile.Close()
-
-			writer := csv.NewWriter(transform.NewWriter(outfile, japanese.ShiftJIS.NewEncoder()))
-			writer.Comma = '\t'
-			writer.UseCRLF = true
-		*/
-
-		excelName := filename + coRec[1] + "健診データ" + day.Format("20060102") + ".xlsx"
-		excelFile := xlsx.NewFile()
-		xlsx.SetDefaultFont(11, "ＭＳ Ｐゴシック")
-		sheet, err := excelFile.AddSheet("データ")
-		failOnError(err)
-
-		// 1行目（タイトル）
-		for I, _ = range cRec {
-			cRec[I] = ""
-		}
-
-		cRec[0] = "idou.sya_bg"
-		cRec[19] = "knk_kenkork.jushin_date"
-		cRec[22] = "knk_kenkork_kensa.kensa_val_071"
-		cRec[27] = "knk_kenkork_kensa.kensa_val_005"
-		cRec[28] = "knk_kenkork_kensa.kensa_val_006"
-		cRec[29] = "knk_kenkork_kensa.kensa_val_007"
-		cRec[31] = "knk_kenkork_kensa.kensa_val_008"
-		cRec[33] = "knk_kenkork_kensa.kensa_val_001"
-		cRec[34] = "knk_kenkork_kensa.kensa_val_002"
-		cRec[35] = "knk_kenkork_kensa.kensa_val_003"
-		cRec[36] = "knk_kenkork_kensa.kensa_val_023"
-		cRec[37] = "knk_kenkork_kensa.kensa_val_027"
-		cRec[38] = "knk_kenkork_kensa.kensa_val_025"
-		cRec[39] = "knk_kenkork_kensa.kensa_val_024"
-		cRec[40] = "knk_kenkork_kensa.kensa_val_028"
-		cRec[41] = "knk_kenkork_kensa.kensa_val_026"
-		cRec[44] = "knk_kenkork_kensa.kensa_val_039"
-		cRec[45] = "knk_kenkork_kensa.kensa_val_038"
-		cRec[46] = "knk_kenkork_kensa.kensa_val_037"
-		cRec[48] = "knk_kenkork_kensa.kensa_val_033"
-		cRec[49] = "knk_kenkork_kensa.kensa_val_034"
-		cRec[50] = "knk_kenkork_kensa.kensa_val_035"
-		cRec[51] = "knk_kenkork_Kensa.kensa_val_080"
-		cRec[54] = "knk_kenkork_kensa.kensa_val_041"
-		cRec[55] = "knk_kenkork_kensa.kensa_val_079"
-		cRec[57] = "knk_kenkork_kensa.kensa_val_042"
-		cRec[64] = "knk_kenkork_kensa.kensa_val_031"
-		cRec[65] = "knk_kenkork_kensa.kensa_val_030"
-		cRec[70] = "knk_kenkork_kensa.kensa_val_047"
-		cRec[72] = "knk_kenkork_kensa.kensa_val_021"
-		cRec[78] = "knk_kenkork_kensa.kensa_val_010"
-		cRec[79] = "knk_kenkork_kensa.kensa_val_011"
-		cRec[80] = "knk_kenkork_kensa.kensa_val_012"
-		cRec[81] = "knk_kenkork_kensa.kensa_val_013"
-		cRec[168] = "knk_kenkork_kensa.kensa_val_072"
-		cRec[176] = "knk_kenkork_kensa.kensa_val_049"
-		cRec[179] = "knk_kenkork_kensa.kensa_val_050"
-		cRec[182] = "knk_kenkork_kensa.kensa_val_051"
-		cRec[185] = "knk_kenkork_kensa.kensa_val_052"
-		cRec[186] = "knk_kenkork_kensa.kensa_val_053"
-		cRec[187] = "knk_kenkork_kensa.kensa_val_054"
-		cRec[188] = "knk_kenkork_kensa.kensa_val_055"
-		cRec[189] = "knk_kenkork_kensa.kensa_val_056"
-		cRec[190] = "knk_kenkork_kensa.kensa_val_057"
-		cRec[191] = "knk_kenkork_kensa.kensa_val_058"
-		cRec[192] = "knk_kenkork_kensa.kensa_val_059"
-		cRec[193] = "knk_kenkork_kensa.kensa_val_060"
-		cRec[194] = "knk_kenkork_kensa.kensa_val_061"
-		cRec[195] = "knk_kenkork_kensa.kensa_val_081"
-		cRec[196] = "knk_kenkork_kensa.kensa_val_062"
-		cRec[197] = "knk_kenkork_kensa.kensa_val_063"
-		cRec[198] = "knk_kenkork_kensa.kensa_val_064"
-		cRec[199] = "knk_kenkork_kensa.kensa_val_082"
-		cRec[200] = "knk_kenkork_kensa.kensa_val_065"
-		cRec[201] = "knk_kenkork_kensa.kensa_val_066"
-		cRec[202] = "knk_kenkork_kensa.kensa_val_067"
-		cRec[203] = "knk_kenkork_kensa.kensa_val_068"
-		cRec[204] = "knk_kenkork_kensa.kensa_val_069"
-		cRec[205] = "knk_kenkork_kensa.kensa_val_070"
-		cRec[212] = "knk_kenkork_kensa.kensa_val_020"
-		cRec[213] = "knk_kenkork_kensa.hantei_val_020"
-		cRec[214] = "knk_kenkork_kensa.kensa_val_044"
-		cRec[215] = "knk_kenkork_kensa.kensa_val_045"
-		cRec[216] = "knk_kenkork_kensa.kensa_val_016"
-		cRec[217] = "knk_kenkork_kensa.kensa_val_017"
-		cRec[218] = "knk_kenkork_kensa.kensa_val_018"
-		cRec[219] = "knk_kenkork_kensa.kensa_val_019"
-		cRec[220] = "knk_kenkork_kensa.kensa_val_046"
-		cRec[221] = "knk_kenkork_kensa.hantei_val_046"
-		//writer.Write(cRec)
-		row := sheet.AddRow()
-		for _, cell = range cRec {
-			//sheet.Cell(0, c).Value = cell
-			vcell = row.AddCell()
-			vcell.Value = cell
-		}
-
-		// 2行目（タイトル）
-		for I, _ = range cRec {
-			cRec[I] = ""
-		}
-
-		cRec[0] = "#社員番号"
-		cRec[19] = "受診日付"
-		cRec[22] = "検査コード071_医療機関側判定結果"
-		cRec[27] = "検査コード005_医療機関側検査値"
-		cRec[28] = "検査コード006_医療機関側検査値"
-		cRec[29] = "検査コード007_医療機関側検査値"
-		cRec[31] = "検査コード008_医療機関側検査値"
-		cRec[33] = "検査コード001_医療機関側検査値"
-		cRec[34] = "検査コード02_医療機関側判定結果"
-		cRec[35] = "検査コード003_医療機関側検査値"
-		cRec[36] = "検査コード023_医療機関側検査値"
-		cRec[37] = "検査コード027_医療機関側検査値"
-		cRec[38] = "検査コード025_医療機関側検査値"
-		cRec[39] = "検査コード024_医療機関側検査値"
-		cRec[40] = "検査コード028_医療機関側検査値"
-		cRec[41] = "検査コード026_医療機関側検査値"
-		cRec[44] = "検査コード039_医療機関側検査値"
-		cRec[45] = "検査コード038_医療機関側検査値"
-		cRec[46] = "検査コード037_医療機関側検査値"
-		cRec[48] = "検査コード033_医療機関側検査値"
-		cRec[49] = "検査コード034_医療機関側検査値"
-		cRec[50] = "検査コード035_医療機関側検査値"
-		cRec[51] = "検査コード080_医療機関側検査値"
-		cRec[54] = "検査コード041_医療機関側検査値"
-		cRec[55] = "検査コード079_医療機関側検査値"
-		cRec[57] = "検査コード042_医療機関側検査値"
-		cRec[64] = "検査コード031_医療機関側検査値"
-		cRec[65] = "検査コード030_医療機関側判定結果"
-		cRec[70] = "検査コード047_医療機関側検査値"
-		cRec[72] = "検査コード021_医療機関側検査値"
-		cRec[78] = "検査コード010_医療機関側検査値"
-		cRec[79] = "検査コード011_医療機関側検査値"
-		cRec[80] = "検査コード012_医療機関側検査値"
-		cRec[81] = "検査コード013_医療機関側検査値"
-		cRec[168] = "検査コード072_医療機関側検査値"
-		cRec[176] = "検査コード049_医療機関側検査値"
-		cRec[179] = "検査コード050_医療機関側検査値"
-		cRec[182] = "検査コード051_医療機関側検査値"
-		cRec[185] = "検査コード052_医療機関側検査値"
-		cRec[186] = "検査コード053_医療機関側検査値"
-		cRec[187] = "検査コード054_医療機関側検査値"
-		cRec[188] = "検査コード055_医療機関側検査値"
-		cRec[189] = "検査コード056_医療機関側検査値"
-		cRec[190] = "検査コード057_医療機関側検査値"
-		cRec[191] = "検査コード058_医療機関側検査値"
-		cRec[192] = "検査コード059_医療機関側検査値"
-		cRec[193] = "検査コード060_医療機関側検査値"
-		cRec[194] = "検査コード061_医療機関側検査値"
-		cRec[195] = "検査コード081_医療機関側検査値"
-		cRec[196] = "検査コード062_医療機関側検査値"
-		cRec[197] = "検査コード063_医療機関側検査値"
-		cRec[198] = "検査コード064_医療機関側検査値"
-		cRec[199] = "検査コード082_医療機関側検査値"
-		cRec[200] = "検査コード065_医療機関側検査値"
-		cRec[201] = "検査コード066_医療機関側検査値"
-		cRec[202] = "検査コード067_医療機関側検査値"
-		cRec[203] = "検査コード068_医療機関側検査値"
-		cRec[204] = "検査コード069_医療機関側検査値"
-		cRec[205] = "検査コード070_医療機関側検査値"
-		cRec[212] = "検査コード020_医療機関側検査値"
-		cRec[213] = "検査コード020_医療機関側検査値"
-		cRec[214] = "検査コード044_医療機関側検査値"
-		cRec[215] = "検査コード045_医療機関側検査値"
-		cRec[216] = "検査コード016_医療機関側検査値"
-		cRec[217] = "検査コード017_医療機関側検査値"
-		cRec[218] = "検査コード018_医療機関側検査値"
-		cRec[219] = "検査コード019_医療機関側検査値"
-		cRec[220] = "検査コード046_医療機関側検査値"
-		cRec[221] = "検査コード046_医療機関側検査値"
-		//writer.Write(cRec)
-		row = sheet.AddRow()
-		for _, cell = range cRec {
-			vcell = row.AddCell()
-			vcell.Value = cell
-		}
-		// 3行目（タイトル）
-		for I, _ = range cRec {
-			cRec[I] = ""
-		}
-
-		cRec[0] = "#従業員番号"
-		cRec[1] = "組合コード"
-		cRec[2] = "受診者ID"
-		cRec[3] = "保険証記号"
-		cRec[4] = "保険証番号"
-		cRec[5] = "続柄"
-		cRec[6] = "枝番"
-		cRec[7] = "所属コード"
-		cRec[8] = "所属名称"
-		cRec[9] = "社員番号"
-		cRec[10] = "加入番号"
-		cRec[11] = "扶養番号"
-		cRec[12] = "受診者区分"
-		cRec[13] = "性別"
-		cRec[14] = "氏名漢字"
-		cRec[15] = "氏名カナ"
-		cRec[16] = "生年月日"
-		cRec[17] = "実施年度"
-		cRec[18] = "年齢"
-		cRec[19] = "受診日"
-		cRec[20] = "健診区分"
-		cRec[21] = "医療機関コード"
-		cRec[22] = "医療機関名称"
-		cRec[23] = "機関コード"
-		cRec[24] = "機関名称"
-		cRec[25] = "機関住所"
-		cRec[26] = "受付NO"
-		cRec[27] = "身長"
-		cRec[28] = "体重"
-		cRec[29] = "BMI"
-		cRec[30] = "内臓脂肪面積"
-		cRec[31] = "腹囲"
-		cRec[32] = "業務歴"
-		cRec[33] = "既往歴"
-		cRec[34] = "自覚症状"
-		cRec[35] = "他覚症状"
-		cRec[36] = "収縮期血圧(その他)"
-		cRec[37] = "収縮期血圧(２回目)"
-		cRec[38] = "収縮期血圧(１回目)"
-		cRec[39] = "拡張期血圧(その他)"
-		cRec[40] = "拡張期血圧(２回目)"
-		cRec[41] = "拡張期血圧(１回目)"
-		cRec[42] = "採血時間"
-		cRec[43] = "総コレステロール"
-		cRec[44] = "中性脂肪"
-		cRec[45] = "HDLコレステロール"
-		cRec[46] = "LDLコレステロール"
-		cRec[47] = "NON-HDLコレステロール"
-		cRec[48] = "GOT(AST)"
-		cRec[49] = "GPT(ALT)"
-		cRec[50] = "γ-GT(γ-GTP)"
-		cRec[51] = "血清クレアチニン"
-		cRec[52] = "eGFR"
-		cRec[53] = "血清尿酸"
-		cRec[54] = "空腹時血糖"
-		cRec[55] = "随時血糖"
-		cRec[56] = "HbA1c"
-		cRec[57] = "HbA1c(NGSP)"
-		cRec[58] = "尿糖"
-		cRec[59] = "尿蛋白"
-		cRec[60] = "尿潜血"
-		cRec[61] = "尿素窒素"
-		cRec[62] = "尿ウロビリノーゲン"
-		cRec[63] = "ヘマトクリット値"
-		cRec[64] = "血色素量(ヘモグロビン値)"
-		cRec[65] = "赤血球数"
-		cRec[66] = "貧血検査実施理由"
-		cRec[67] = "白血球数"
-		cRec[68] = "血小板数"
-		cRec[69] = "血清アミラーゼ"
-		cRec[70] = "心電図(所見)"
-		cRec[71] = "心電図(実施理由)"
-		cRec[72] = "胸部X線検査(所見)"
-		cRec[73] = "胸部X線検査(撮影年月日)"
-		cRec[74] = "喀痰検査(塗抹鏡検 一般細菌)(所見)"
-		cRec[75] = "喀痰検査(塗抹鏡検 抗酸菌)"
-		cRec[76] = "喀痰検査(ガフキー号数)"
-		cRec[77] = "便潜血"
-		cRec[78] = "視力(裸眼右)"
-		cRec[79] = "視力(矯正右)"
-		cRec[80] = "視力(裸眼左)"
-		cRec[81] = "視力(矯正左)"
-		cRec[82] = "聴力(右1000Hz)"
-		cRec[83] = "聴力(右4000Hz)"
-		cRec[84] = "聴力(左1000Hz)"
-		cRec[85] = "聴力(左4000Hz)"
-		cRec[86] = "聴力(その他の所見)"
-		cRec[87] = "眼底検査(キースワグナー分類)"
-		cRec[88] = "眼底検査(シェイエ分類:H)"
-		cRec[89] = "眼底検査(シェイエ分類:S)"
-		cRec[90] = "眼底検査(SCOTT分類)"
-		cRec[91] = "眼底検査（wong-Mitchell分類）"
-		cRec[92] = "眼底検査（改変Davis分類）"
-		cRec[93] = "眼底検査(その他の所見)"
-		cRec[94] = "眼底検査(実施理由)"
-		cRec[95] = "その他の法定特殊健康診断"
-		cRec[96] = "その他の法定検査"
-		cRec[97] = "その他の検査"
-		cRec[98] = "追加項目1"
-		cRec[99] = "追加項目2"
-		cRec[100] = "追加項目3"
-		cRec[101] = "追加項目4"
-		cRec[102] = "追加項目5"
-		cRec[103] = "追加項目6"
-		cRec[104] = "追加項目7"
-		cRec[105] = "追加項目8"
-		cRec[106] = "追加項目9"
-		cRec[107] = "追加項目10"
-		cRec[108] = "BMI判定"
-		cRec[109] = "内臓脂肪面積判定"
-		cRec[110] = "腹囲判定"
-		cRec[111] = "血圧判定"
-		cRec[112] = "総コレステロール判定"
-		cRec[113] = "中性脂肪判定"
-		cRec[114] = "HDLコレステロール判定"
-		cRec[115] = "LDLコレステロール判定"
-		cRec[116] = "NON-HDLコレステロール判定"
-		cRec[117] = "GOT(AST)判定"
-		cRec[118] = "GPT(ALT)判定"
-		cRec[119] = "γ-GT(γ-GTP)判定"
-		cRec[120] = "血清クレアチニン判定"
-		cRec[121] = "eGFR判定"
-		cRec[122] = "血清尿酸判定"
-		cRec[123] = "空腹時血糖判定"
-		cRec[124] = "随時血糖判定"
-		cRec[125] = "HbA1c判定"
-		cRec[126] = "HbA1c（NGSP)判定"
-		cRec[127] = "尿糖判定"
-		cRec[128] = "尿蛋白判定"
-		cRec[129] = "尿潜血判定"
-		cRec[130] = "尿素窒素判定"
-		cRec[131] = "尿ウロビリノーゲン判定"
-		cRec[132] = "ヘマトクリット値判定"
-		cRec[133] = "血色素量(ヘモグロビン値)判定"
-		cRec[134] = "赤血球数判定"
-		cRec[135] = "白血球数判定"
-		cRec[136] = "血小板数判定"
-		cRec[137] = "視力(右)判定"
-		cRec[138] = "視力(左)判定"
-		cRec[139] = "追加項目判定1"
-		cRec[140] = "追加項目判定2"
-		cRec[141] = "追加項目判定3"
-		cRec[142] = "追加項目判定4"
-		cRec[143] = "追加項目判定5"
-		cRec[144] = "追加項目判定6"
-		cRec[145] = "追加項目判定7"
-		cRec[146] = "追加項目判定8"
-		cRec[147] = "追加項目判定9"
-		cRec[148] = "追加項目判定10"
-		cRec[149] = "コメント"
-		cRec[150] = "総合判定"
-		cRec[151] = "受診勧奨区分"
-		cRec[152] = "指導状態"
-		cRec[153] = "再検査区分"
-		cRec[154] = "一次健診日"
-		cRec[155] = "結果通知区分"
-		cRec[156] = "メタボリック判定(血圧リスク)"
-		cRec[157] = "メタボリック判定(血糖リスク)"
-		cRec[158] = "メタボリック判定(脂質リスク)"
-		cRec[159] = "メタボリック判定(リスクカウント)"
-		cRec[160] = "支援レベル(血圧リスク)"
-		cRec[161] = "支援レベル(血糖リスク)"
-		cRec[162] = "支援レベル(脂質リスク)"
-		cRec[163] = "支援レベル(喫煙リスク)"
-		cRec[164] = "支援レベル(リスクカウント)"
-		cRec[165] = "メタボリックシンドローム判定"
-		cRec[166] = "支援レベル"
-		cRec[167] = "医師の診断(判定)"
-		cRec[168] = "健康診断を実施した医師の氏名"
-		cRec[169] = "医師の意見"
-		cRec[170] = "意見を述べた医師の氏名"
-		cRec[171] = "歯科医師による健康診断"
-		cRec[172] = "歯科医師による健康診断を実施した歯科医師の氏名"
-		cRec[173] = "歯科医師の意見"
-		cRec[174] = "意見を述べた歯科医師の氏名"
-		cRec[175] = "備考"
-		cRec[176] = "服薬１_血圧"
-		cRec[177] = "血圧_薬剤"
-		cRec[178] = "血圧_服薬理由"
-		cRec[179] = "服薬２_血糖"
-		cRec[180] = "血糖_薬剤"
-		cRec[181] = "血糖_服薬理由"
-		cRec[182] = "服薬３_脂質"
-		cRec[183] = "脂質_薬剤"
-		cRec[184] = "脂質_服薬理由"
-		cRec[185] = "既往歴１_脳血管"
-		cRec[186] = "既往歴２_心血管"
-		cRec[187] = "既往歴３_腎不全人工透析"
-		cRec[188] = "貧血"
-		cRec[189] = "喫煙"
-		cRec[190] = "２０歳からの体重変化"
-		cRec[191] = "３０分以上の運動習慣"
-		cRec[192] = "歩行又は身体活動"
-		cRec[193] = "歩行速度"
-		cRec[194] = "１年間の体重変化"
-		cRec[195] = "食事についての咀嚼"
-		cRec[196] = "食べ方１_早食い等"
-		cRec[197] = "食べ方２_就寝前"
-		cRec[198] = "食べ方３_夜食間食"
-		cRec[199] = "食べ方３_三食以外の間食"
-		cRec[200] = "食習慣"
-		cRec[201] = "飲酒"
-		cRec[202] = "飲酒量"
-		cRec[203] = "睡眠"
-		cRec[204] = "生活習慣の改善"
-		cRec[205] = "保健指導の希望"
-		cRec[206] = "報告対象区分"
-		cRec[207] = "保健指導からの除外"
-		cRec[208] = "取込年月日"
-		cRec[209] = "胸部X線判定①"
-		cRec[210] = "胸部X線判定②"
-		cRec[211] = "心電図判定"
-		cRec[212] = "胸部レントゲン検査"
-		cRec[213] = "胸部レントゲン判定"
-		cRec[214] = "尿糖"
-		cRec[215] = "尿蛋白"
-		cRec[216] = "聴力(右1000Hz)"
-		cRec[217] = "聴力(右4000Hz)"
-		cRec[218] = "聴力(左1000Hz)"
-		cRec[219] = "聴力(左4000Hz)"
-		cRec[220] = "心電図検査"
-		cRec[221] = "心電図判定"
-		//writer.Write(cRec)
-		row = sheet.AddRow()
-		for _, cell = range cRec {
-			vcell = row.AddCell()
-			vcell.Value = cell
-		}
-
-		// 4行目移行（データ）
-		r = 3
 		inRecsMax := len(inRecs)
 		for J := 1; J < inRecsMax; J++ {
-			for I, _ = range cRec {
-				cRec[I] = ""
+			if inRecs[J][4] != coRec[0] || !coseCheck(inRecs[J][13]) {
+				continue
 			}
 
-			if inRecs[J][4] == coRec[0] && coseCheck(inRecs[J][13]) {
-				// 0.社員番号
-				if len(inRecs[J][0]) != 10 {
-					log.Printf("社員番号が10桁ではありません:%v\r\n", inRecs[J][0])
-				}
-				cRec[0] = inRecs[J][0]
-
-				// 1.組合コード
-
-				// 2.受診者ID
-				cRec[2] = inRecs[J][1]
-
-				// 3.保険証記号
-				cRec[3] = inRecs[J][2]
-
-				// 4.保険証番号
-				cRec[4] = inRecs[J][3]
-
-				// 5.続柄
-				// 6.枝番
-				// 7.所属コード
-				cRec[7] = inRecs[J][6]
-
-				// 8.所属名称
-				cRec[8] = inRecs[J][7]
-
-				// 9.社員番号
-				cRec[9] = inRecs[J][0]
-
-				// 10.加入番号
-
-				// 11.扶養番号
-
-				// 12.受診者区分
-
-				// 13.性別
-				cRec[13] = inRecs[J][8]
-
-				// 14.氏名漢字
-				cRec[14] = inRecs[J][9]
-
-				// 15.氏名カナ
-				cRec[15] = string(norm.NFKC.Bytes([]byte(inRecs[J][10])))
-
-				// 16.生年月日
-				cRec[16] = WaToSeireki(inRecs[J][11])
-
-				// 17.実施年度
-				cRec[17] = nendo(inRecs[J][15])
-
-				// 18.年齢
-				cRec[18] = inRecs[J][12]
-
-				// 19.受診日
-				cRec[19] = strings.Replace(inRecs[J][15], "-", "/", -1)
-
-				// 20.健診区分
-				cRec[20] = "事業者健診"
-
-				// 21.医療機関コード
-				cRec[21] = "013-61"
-
-				// 22.医療機関名称
-				cRec[22] = "医療法人社団　松英会"
-
-				// 23.機関コード
-				cRec[23] = "1311131242"
-
-				// 24.機関名称
-				cRec[24] = "医療法人社団　松英会"
-
-				// 25.機関住所
-				cRec[25] = "143-0027 大田区中馬込1-5-8"
-
-				// 26.受付NO
-				cRec[26] = inRecs[J][16]
-
-				// 27.身長
-				cRec[27] = inRecs[J][17]
-
-				// 28.体重
-				cRec[28] = inRecs[J][18]
-
-				// 29.BMI
-				cRec[29] = inRecs[J][19]
-
-				// 30.内臓脂肪面積
-
-				// 31.腹囲
-				cRec[31] = inRecs[J][20]
-
-				// 32.業務歴
+			// 健保組合へは項目の制限の前のデータから、事業者へは制限を適用したデータを作る
+			cRec, kiouCodes := recConversion(inRecs[J], coRec, head[2])
+
+			// 履歴には制限の前のデータを、送付しない理由と既往歴のICD-10・制限した列を付けて残す
+			retsu := seigenRetsu(seigenFind(coRec[0], inRecs[J]))
+			key := coRec[0] + "\t" + rirekiKey(cRec)
+			keinenSeigen[key] = strings.Trim(keinenSeigen[key]+","+retsu, ",")
+			jyoutai := ""
+			if riyu := jogaiCheck(inRecs[J]); riyu != "" {
+				jyoutai = "提供除外:" + riyu
+			} else if riyu := youkakunin[inRecs[J][1]]; riyu != "" && honninCheck(inRecs[J]) {
+				jyoutai = "要確認:" + riyu
+			}
+			rireki = append(rireki, append(append([]string{}, cRec...), jyoutai, strings.Join(kiouCodes, " "), retsu))
 
-				// 33.既往歴
-				kiou := ""
-				for k := 0; k < 10; k++ {
-					kp := 21 + (k * 2)
-					kiouB := kiouSet(inRecs[J][kp])
-					kiouT := kiouSet(inRecs[J][kp+1])
-					if kiouB != "" {
-						if utf8.RuneCountInString(kiou+" "+kiouB+kiouT) > 25 {
-							if utf8.RuneCountInString(kiou+" "+kiouB) > 25 {
-								break
-							} else {
-								if kiou == "" {
-									kiou = kiouB
-								} else {
-									kiou = kiou + " " + kiouB
-								}
-							}
-						} else {
-							if kiou == "" {
-								kiou = kiouB + kiouT
-							} else {
-								kiou = kiou + " " + kiouB + kiouT
-							}
-						}
-					}
+			if !outCheck(inRecs[J], coRec[0]) {
+				continue
+			}
+			if honninCheck(inRecs[J]) {
+				if youkakunin[inRecs[J][1]] == "" {
+					jigyosya = append(jigyosya, jigyosyaRec(cRec, inRecs[J], coRec, head[2]))
 				}
+				if tokuteiCheck(cRec) {
+					kenpo = append(kenpo, kenpoRec(tokuteiRec(cRec), head[2]))
+				}
+			} else {
+				kenpo = append(kenpo, kenpoRec(append([]string{}, cRec...), head[2]))
+			}
+		}
 
-				cRec[33] = kiou
-
-				// 34.自覚症状
-				cRec[34] = syoken(inRecs[J][41] + " " + inRecs[J][42] + " " + inRecs[J][43])
-
-				// 35.他覚症状
-				cRec[35] = syoken(inRecs[J][44] + " " + inRecs[J][45] + " " + inRecs[J][46])
-
-				// 36.収縮期血圧(その他)
-
-				// 37.収縮期血圧(２回目)
-				cRec[37] = inRecs[J][47]
-
-				// 38.収縮期血圧(１回目)
-				cRec[38] = inRecs[J][48]
-
-				// 39.拡張期血圧(その他)
+		if *sabunMode {
+			// 前回までに送付した方を除く
+			sabunSave(filename, "健診データ", day.Format("20060102"), "健診データ", coRec, head, jigyosya)
+			sabunSave(kenpoDir, "健診データ（健保組合提出用）", day.Format("20060102"), "健保組合提出用", coRec, head, kenpo)
+		} else {
+			dataSave(filename+coRec[1]+"健診データ"+day.Format("20060102")+".xlsx", coRec, "健診データ", head, jigyosya, nil)
+			if len(kenpo) > 0 {
+				dataSave(kenpoDir+coRec[1]+"健診データ（健保組合提出用）"+day.Format("20060102")+".xlsx", coRec, "健保組合提出用", head, kenpo, nil)
+			}
+		}
+		rirekiSave(coRec, head[2], rireki)
+		reportSave(filename+coRec[1]+"健診結果レポート"+day.Format("20060102")+".html", coRec[1], head[2], jigyosya)
+	}
 
-				// 40.拡張期血圧(２回目)
-				cRec[40] = inRecs[J][49]
+}
 
-				// 41.拡張期血圧(１回目)
-				cRec[41] = inRecs[J][50]
+// 健診データを保存して検証する（kubunは「健診データ」か「健保組合提出用」）
+// 経年・訂正箇所のシートは保存と検証の前に、形式毎に分けたファイルのそれぞれの受診者の分を付ける
+func dataSave(excelName string, coRec []string, kubun string, head [][]string, cRecs [][]string, kasyo [][]string) {
+	var vcell *xlsx.Cell
 
-				// 42.採血時間
+	/*
+		outfile, err := os.Create(filename + coRec[1] + "健診データ" + day.Format("20060102") + ".txt")
+		failOnError(err)
+		defer outfile.Close()
 
-				// 43.総コレステロール
-				cRec[43] = inRecs[J][53]
+		writer := csv.NewWriter(transform.NewWriter(outfile, japanese.ShiftJIS.NewEncoder()))
+		writer.Comma = '\t'
+		writer.UseCRLF = true
+	*/
 
-				// 44.中性脂肪
-				cRec[44] = inRecs[J][54]
+	// 書式の確認（誤りがあれば保存しない）
+	if !syoshikiKakunin(excelName, kubun, head[2], cRecs) {
+		return
+	}
 
-				// 45.HDLコレステロール
-				cRec[45] = inRecs[J][55]
+	// 受診者の実施年度の形式に並べ替える（形式が複数あればファイルを分ける）
+	yousikis, bunrui := yousikiBunrui(cRecs)
+	for i, y := range yousikis {
+		cols := yousikiCol(y, head[2])
+		yName := yousikiName(excelName, y, i)
+		yHead := yousikiHead(y, head, cols)
+		yRecs := make([][]string, 0)
+		for _, cRec := range bunrui[y.name] {
+			yRecs = append(yRecs, yousikiHokan(y, head[2], cRec, yousikiRec(cRec, cols)))
+		}
+		if y.name != yousikiList[0].name {
+			log.Printf("%v年形式で出力しました:%v %v件\r\n", y.name, yName, len(yRecs))
+		}
 
-				// 46.LDLコレステロール
-				cRec[46] = inRecs[J][56]
+		excelFile := xlsx.NewFile()
+		xlsx.SetDefaultFont(11, "ＭＳ Ｐゴシック")
+		sheet, err := excelFile.AddSheet("データ")
+		failOnError(err)
 
-				// 47.NON-HDLコレステロール
-				cRec[47] = inRecs[J][57]
+		// 1～3行目がタイトル、4行目移行がデータ
+		rows := append(append([][]string{}, yHead...), yRecs...)
+		for _, cRec := range rows {
+			//writer.Write(cRec)
+			row := sheet.AddRow()
+			for _, cell := range cRec {
+				vcell = row.AddCell()
+				vcell.Value = cell
+			}
+		}
 
-				// 48.GOT(AST)
-				cRec[48] = inRecs[J][58]
+		if *keinenNen > 0 && kubun == "健診データ" {
+			keinenSheet(excelFile, coRec, head[2], bunrui[y.name])
+		}
+		if len(kasyo) > 0 {
+			kasyoSheet(excelFile, kasyo, bunrui[y.name])
+		}
+		if kubun == "健保組合提出用" {
+			soufuhyouSheet(excelFile, coRec, y.name, len(yRecs))
+		}
 
-				// 49.GPT(ALT)
-				cRec[49] = inRecs[J][59]
+		//writer.Flush()
+		err = excelFile.Save(yName)
+		failOnError(err)
 
-				// 50.γ-GT(γ-GTP)
-				cRec[50] = inRecs[J][60]
+		kensyou(yName, yHead, yRecs, cols, coRec[0], kubun)
+	}
+}
 
-				// 51.血清クレアチニン
-				cRec[51] = inRecs[J][61]
+// 受診者1人分の健診データ（項目の制限・文字数の制限の前のすべての項目）と既往歴のICD-10
+// 事業者へ提出するデータはjigyosyaRec、健保組合へ提出するデータはkenpoRecで作る
+func recConversion(inRec []string, coRec []string, head []string) ([]string, []string) {
+	cRec := make([]string, len(head))
 
-				// 52.eGFR
-				cRec[52] = inRecs[J][62]
+	// 0.社員番号（syainCheckでそろえたもの）
+	cRec[0] = inRec[0]
 
-				// 53.血清尿酸
-				cRec[53] = inRecs[J][63]
+	// 1.組合コード
+	cRec[1] = coRec[3]
 
-				// 54.空腹時血糖
-				// 55.随時血糖
-				if syokugo(inRecs[J][51], inRecs[J][52]) {
-					cRec[55] = inRecs[J][64]
-				} else {
-					cRec[54] = inRecs[J][64]
-				}
+	// 2.受診者ID
+	cRec[2] = inRec[1]
 
-				// 56.HbA1c
-				// 57.HbA1c(NGSP)
-				cRec[57] = inRecs[J][65]
+	// 3.保険証記号
+	cRec[3] = hokenNorm(inRec[2])
 
-				// 58.尿糖
-				cRec[58] = nyouT(inRecs[J][66])
+	// 4.保険証番号
+	cRec[4] = hokenNorm(inRec[3])
+	hokenCheck(cRec[3], cRec[4], inRec[1], coRec[1])
 
-				// 59.尿蛋白
-				cRec[59] = nyouT(inRecs[J][67])
+	// 5.続柄
+	// 6.枝番
+	// 11.扶養番号
+	// 12.受診者区分
+	zokugaraSet(cRec, inRec)
 
-				// 60.尿潜血
-				cRec[60] = inRecs[J][68]
+	// 7.所属コード
+	cRec[7] = inRec[6]
 
-				// 61.尿素窒素
-				cRec[61] = inRecs[J][69]
+	// 8.所属名称
+	cRec[8] = inRec[7]
 
-				// 62.尿ウロビリノーゲン
-				cRec[62] = inRecs[J][70]
+	// 9.社員番号
+	cRec[9] = inRec[0]
 
-				// 63.ヘマトクリット値
-				cRec[63] = inRecs[J][71]
+	// 10.加入番号
 
-				// 64.血色素量(ヘモグロビン値)
-				cRec[64] = inRecs[J][72]
+	// 11.扶養番号（zokugaraSetで設定）
 
-				// 65.赤血球数
-				cRec[65] = inRecs[J][73]
+	// 12.受診者区分（zokugaraSetで設定）
 
-				// 66.貧血検査実施理由
+	// 13.性別
+	cRec[13] = inRec[8]
 
-				// 67.白血球数
-				cRec[67] = inRecs[J][74]
+	// 14.氏名漢字
+	cRec[14] = inRec[9]
 
-				// 68.血小板数
-				cRec[68] = inRecs[J][75]
+	// 15.氏名カナ
+	cRec[15] = string(norm.NFKC.Bytes([]byte(inRec[10])))
 
-				// 69.血清アミラーゼ
-				cRec[69] = inRecs[J][76]
+	// 16.生年月日
+	cRec[16] = WaToSeireki(inRec[11])
 
-				// 70.心電図(所見)
-				cRec[70] = syoken(inRecs[J][78] + " " + inRecs[J][79] + " " + inRecs[J][80] + " " + inRecs[J][81])
+	// 17.実施年度
+	cRec[17] = nendo(inRec[15])
 
-				// 71.心電図(実施理由)
+	// 18.年齢
+	cRec[18] = inRec[12]
 
-				// 72.胸部X線検査(所見)
-				cRec[72] = syoken(inRecs[J][83] + " " + inRecs[J][84] + " " + inRecs[J][85])
+	// 19.受診日
+	cRec[19] = strings.Replace(inRec[15], "-", "/", -1)
 
-				// 73.胸部X線検査(撮影年月日)
-				if inRecs[J][82] != "" {
-					cRec[73] = strings.Replace(inRecs[J][15], "-", "/", -1)
-				}
+	// 20.健診区分
+	cRec[20] = "事業者健診"
 
-				// 74.喀痰検査(塗抹鏡検 一般細菌)(所見)
+	// 21.医療機関コード
+	cRec[21] = "013-61"
 
-				// 75.喀痰検査(塗抹鏡検 抗酸菌)
+	// 22.医療機関名称
+	cRec[22] = "医療法人社団　松英会"
 
-				// 76.喀痰検査(ガフキー号数)
+	// 23.機関コード
+	cRec[23] = "1311131242"
 
-				// 77.便潜血
-				cRec[77] = inRecs[J][86]
+	// 24.機関名称
+	cRec[24] = "医療法人社団　松英会"
 
-				// 78.視力(裸眼右)
-				cRec[78] = eye(inRecs[J][87])
+	// 25.機関住所
+	cRec[25] = "143-0027 大田区中馬込1-5-8"
 
-				// 79.視力(矯正右)
-				cRec[79] = eye(inRecs[J][88])
+	// 26.受付NO
+	cRec[26] = inRec[16]
 
-				// 80.視力(裸眼左)
-				cRec[80] = eye(inRecs[J][89])
+	// 27.身長
+	cRec[27] = inRec[17]
 
-				// 81.視力(矯正左)
-				cRec[81] = eye(inRecs[J][90])
+	// 28.体重
+	cRec[28] = inRec[18]
 
-				// 82.聴力(右1000Hz)
-				cRec[82] = syokenumu(inRecs[J][99])
+	// 29.BMI
+	cRec[29] = inRec[19]
 
-				// 83.聴力(右4000Hz)
-				cRec[83] = syokenumu4k(inRecs[J][101], inRecs[J][103])
+	// 30.内臓脂肪面積
 
-				// 84.聴力(左1000Hz)
-				cRec[84] = syokenumu(inRecs[J][100])
+	// 31.腹囲
+	cRec[31] = inRec[20]
 
-				// 85.聴力(左4000Hz)
-				cRec[85] = syokenumu4k(inRecs[J][102], inRecs[J][104])
+	// 32.業務歴
 
-				// 86.聴力(その他の所見)
+	// 33.既往歴（辞書で標準病名にそろえる。文字数を超えた分はmojisuCheckで備考へ移す）
+	kiou, kiouCodes := kiouCode(inRec, nil)
+	cRec[33] = kiou
 
-				// 87.眼底検査(キースワグナー分類)
+	// 34.自覚症状
+	cRec[34] = syokenJisyo(syojyouJisyo, "症状辞書", inRec[41], inRec[42], inRec[43])
 
-				// 88.眼底検査(シェイエ分類:H)
+	// 35.他覚症状
+	cRec[35] = syokenJisyo(syojyouJisyo, "症状辞書", inRec[44], inRec[45], inRec[46])
 
-				// 89.眼底検査(シェイエ分類:S)
+	// 36.収縮期血圧(その他)
 
-				// 90.眼底検査(SCOTT分類)
+	// 37.収縮期血圧(２回目)
+	cRec[37] = inRec[47]
 
-				// 91.眼底検査(wong-Mitchell分類)
+	// 38.収縮期血圧(１回目)
+	cRec[38] = inRec[48]
 
-				// 92.眼底検査(改変Davis分類)
+	// 39.拡張期血圧(その他)
 
-				// 93.眼底検査(その他の所見)
+	// 40.拡張期血圧(２回目)
+	cRec[40] = inRec[49]
 
-				// 94.眼底検査(実施理由)
+	// 41.拡張期血圧(１回目)
+	cRec[41] = inRec[50]
 
-				// 95.その他の法定特殊健康診断
+	// 42.採血時間
 
-				// 96.その他の法定検査
+	// 43.総コレステロール
+	cRec[43] = inRec[53]
 
-				// 97.その他の検査
+	// 44.中性脂肪
+	cRec[44] = inRec[54]
 
-				// 98.追加項目1
+	// 45.HDLコレステロール
+	cRec[45] = inRec[55]
 
-				// 99.追加項目2
+	// 46.LDLコレステロール
+	cRec[46] = inRec[56]
 
-				// 100.追加項目3
+	// 47.NON-HDLコレステロール
+	cRec[47] = inRec[57]
 
-				// 101.追加項目4
+	// 48.GOT(AST)
+	cRec[48] = inRec[58]
 
-				// 102.追加項目5
+	// 49.GPT(ALT)
+	cRec[49] = inRec[59]
 
-				// 103.追加項目6
+	// 50.γ-GT(γ-GTP)
+	cRec[50] = inRec[60]
 
-				// 104.追加項目7
+	// 51.血清クレアチニン
+	cRec[51] = inRec[61]
 
-				// 105.追加項目8
+	// 52.eGFR
+	cRec[52] = inRec[62]
 
-				// 106.追加項目9
+	// 53.血清尿酸
+	cRec[53] = inRec[63]
 
-				// 107.追加項目10
+	// 54.空腹時血糖
+	// 55.随時血糖
+	if syokugo(inRec[51], inRec[52]) {
+		cRec[55] = inRec[64]
+	} else {
+		cRec[54] = inRec[64]
+	}
 
-				// 108.BMI判定
-				cRec[108] = inRecs[J][105]
+	// 56.HbA1c
+	// 57.HbA1c(NGSP)
+	cRec[57] = inRec[65]
 
-				// 109.内臓脂肪面積判定
+	// 58.尿糖
+	cRec[58] = nyouT(inRec[66])
 
-				// 110.腹囲判定
-				cRec[110] = inRecs[J][106]
+	// 59.尿蛋白
+	cRec[59] = nyouT(inRec[67])
 
-				// 111.血圧判定
-				cRec[111] = string(norm.NFKC.Bytes([]byte(inRecs[J][107])))
+	// 60.尿潜血
+	cRec[60] = inRec[68]
 
-				// 112.総コレステロール判定
-				cRec[112] = inRecs[J][108]
+	// 61.尿素窒素
+	cRec[61] = inRec[69]
 
-				// 113.中性脂肪判定
-				cRec[113] = inRecs[J][109]
+	// 62.尿ウロビリノーゲン
+	cRec[62] = inRec[70]
 
-				// 114.HDLコレステロール判定
-				cRec[114] = inRecs[J][110]
+	// 63.ヘマトクリット値
+	cRec[63] = inRec[71]
 
-				// 115.LDLコレステロール判定
-				cRec[115] = inRecs[J][111]
+	// 64.血色素量(ヘモグロビン値)
+	cRec[64] = inRec[72]
 
-				// 116.NON-HDLコレステロール判定
-				cRec[116] = inRecs[J][112]
+	// 65.赤血球数
+	cRec[65] = inRec[73]
 
-				// 117.GOT(AST)判定
-				cRec[117] = inRecs[J][113]
+	// 66.貧血検査実施理由（syousaiSetで設定）
 
-				// 118.GPT(ALT)判定
-				cRec[118] = inRecs[J][114]
+	// 67.白血球数
+	cRec[67] = inRec[74]
 
-				// 119.γ-GT(γ-GTP)判定
-				cRec[119] = inRecs[J][115]
+	// 68.血小板数
+	cRec[68] = inRec[75]
 
-				// 120.血清クレアチニン判定
-				cRec[120] = inRecs[J][116]
+	// 69.血清アミラーゼ
+	cRec[69] = inRec[76]
 
-				// 121.eGFR判定
-				cRec[121] = inRecs[J][117]
+	// 70.心電図(所見)
+	cRec[70] = syokenJisyo(ecgJisyo, "心電図辞書", inRec[78], inRec[79], inRec[80], inRec[81])
 
-				// 122.血清尿酸判定
-				cRec[122] = inRecs[J][118]
+	// 71.心電図(実施理由)（syousaiSetで設定）
 
-				// 123.空腹時血糖判定
-				// 124.随時血糖判定
-				if syokugo(inRecs[J][51], inRecs[J][52]) {
-					cRec[124] = toH(inRecs[J][64])
-				} else {
-					cRec[123] = inRecs[J][119]
-				}
-
-				// 125.HbA1c判定
+	// 72.胸部X線検査(所見)
+	cRec[72] = syokenJisyo(xrayJisyo, "胸部X線辞書", inRec[83], inRec[84], inRec[85])
 
-				// 126.HbA1c（NGSP)判定
-				cRec[126] = inRecs[J][120]
+	// 73.胸部X線検査(撮影年月日)
+	xray1 := string(norm.NFKC.Bytes([]byte(inRec[82])))
+	xray2 := string(norm.NFKC.Bytes([]byte(inVal(inRec, "胸部X線判定②", "胸部X線二次判定", "二次読影判定"))))
+	cRec[73] = satsueibi(inRec, xray1 != "" || cRec[72] != "")
 
-				// 127.尿糖判定
-				cRec[127] = inRecs[J][121]
+	// 74.喀痰検査(塗抹鏡検 一般細菌)(所見)
 
-				// 128.尿蛋白判定
-				cRec[128] = inRecs[J][122]
+	// 75.喀痰検査(塗抹鏡検 抗酸菌)
 
-				// 129.尿潜血判定
-				cRec[129] = inRecs[J][123]
+	// 76.喀痰検査(ガフキー号数)
 
-				// 130.尿素窒素判定
-				cRec[130] = inRecs[J][124]
+	// 77.便潜血
+	cRec[77] = inRec[86]
 
-				// 131.尿ウロビリノーゲン判定
-				cRec[131] = inRecs[J][125]
+	// 78.視力(裸眼右)
+	cRec[78] = eye(inRec[87])
 
-				// 132.ヘマトクリット値判定
-				cRec[132] = inRecs[J][126]
+	// 79.視力(矯正右)
+	cRec[79] = eye(inRec[88])
 
-				// 133.血色素量(ヘモグロビン値)判定
-				cRec[133] = inRecs[J][127]
+	// 80.視力(裸眼左)
+	cRec[80] = eye(inRec[89])
 
-				// 134.赤血球数判定
-				cRec[134] = inRecs[J][128]
+	// 81.視力(矯正左)
+	cRec[81] = eye(inRec[90])
 
-				// 135.白血球数判定
-				cRec[135] = inRecs[J][129]
+	// 82.聴力(右1000Hz)
+	cRec[82] = syokenumu1k(inRec[99])
 
-				// 136.血小板数判定
-				cRec[136] = inRecs[J][130]
+	// 83.聴力(右4000Hz)
+	cRec[83] = syokenumu4k(inRec[101], inRec[103])
 
-				// 137.視力(右)判定
-				cRec[137] = eyeHantei(inRecs[J][131], inRecs[J][132])
+	// 84.聴力(左1000Hz)
+	cRec[84] = syokenumu1k(inRec[100])
 
-				// 138.視力(左)判定
-				cRec[138] = eyeHantei(inRecs[J][133], inRecs[J][134])
+	// 85.聴力(左4000Hz)
+	cRec[85] = syokenumu4k(inRec[102], inRec[104])
 
-				// 139.追加項目判定1
+	// 86.聴力(その他の所見)
+	cRec[86] = chouryokuSonota(inRec)
 
-				// 140.追加項目判定2
+	// 87.眼底検査(キースワグナー分類)
+	// 88.眼底検査(シェイエ分類:H)
+	// 89.眼底検査(シェイエ分類:S)
+	// 90.眼底検査(SCOTT分類)
+	// 91.眼底検査(wong-Mitchell分類)
+	// 92.眼底検査(改変Davis分類)
+	// 93.眼底検査(その他の所見)
+	// 94.眼底検査(実施理由)
+	// （syousaiSetで設定）
 
-				// 141.追加項目判定3
+	// 95.その他の法定特殊健康診断
 
-				// 142.追加項目判定4
+	// 96.その他の法定検査
 
-				// 143.追加項目判定5
+	// 97.その他の検査
 
-				// 144.追加項目判定6
+	// 98～107.追加項目1～10（コース毎の設定に従う）
+	tsuikaSet(cRec, inRec)
 
-				// 145.追加項目判定7
+	// 108.BMI判定
+	cRec[108] = inRec[105]
 
-				// 146.追加項目判定8
+	// 109.内臓脂肪面積判定
 
-				// 147.追加項目判定9
+	// 110.腹囲判定
+	cRec[110] = inRec[106]
 
-				// 148.追加項目判定10
+	// 111.血圧判定
+	cRec[111] = string(norm.NFKC.Bytes([]byte(inRec[107])))
 
-				// 149.コメント
+	// 112.総コレステロール判定
+	cRec[112] = inRec[108]
 
-				// 150.総合判定
-				if inRecs[J][135] == "" {
-					log.Print("総合判定が抜けている方がいます。")
-				}
-				cRec[150] = inRecs[J][135]
+	// 113.中性脂肪判定
+	cRec[113] = inRec[109]
 
-				// 151.受診勧奨区分
+	// 114.HDLコレステロール判定
+	cRec[114] = inRec[110]
 
-				// 152.指導状態
+	// 115.LDLコレステロール判定
+	cRec[115] = inRec[111]
 
-				// 153.再検査区分
+	// 116.NON-HDLコレステロール判定
+	cRec[116] = inRec[112]
 
-				// 154.一次健診日
+	// 117.GOT(AST)判定
+	cRec[117] = inRec[113]
 
-				// 155.結果通知区分
+	// 118.GPT(ALT)判定
+	cRec[118] = inRec[114]
 
-				// 156.メタボリック判定(血圧リスク)
+	// 119.γ-GT(γ-GTP)判定
+	cRec[119] = inRec[115]
 
-				// 157.メタボリック判定(血糖リスク)
+	// 120.血清クレアチニン判定
+	cRec[120] = inRec[116]
 
-				// 158.メタボリック判定(脂質リスク)
+	// 121.eGFR判定
+	cRec[121] = inRec[117]
 
-				// 159.メタボリック判定(リスクカウント)
+	// 122.血清尿酸判定
+	cRec[122] = inRec[118]
 
-				// 160.支援レベル(血圧リスク)
+	// 123.空腹時血糖判定
+	// 124.随時血糖判定
+	if syokugo(inRec[51], inRec[52]) {
+		cRec[124] = toH(inRec[64])
+	} else {
+		cRec[123] = inRec[119]
+	}
 
-				// 161.支援レベル(血糖リスク)
+	// 125.HbA1c判定
 
-				// 162.支援レベル(脂質リスク)
+	// 126.HbA1c（NGSP)判定
+	cRec[126] = inRec[120]
 
-				// 163.支援レベル(喫煙リスク)
+	// 127.尿糖判定
+	cRec[127] = inRec[121]
 
-				// 164.支援レベル(リスクカウント)
+	// 128.尿蛋白判定
+	cRec[128] = inRec[122]
 
-				// 165.メタボリックシンドローム判定
-				cRec[165] = inRecs[J][137]
+	// 129.尿潜血判定
+	cRec[129] = inRec[123]
 
-				// 166.支援レベル
-				cRec[166] = inRecs[J][138]
+	// 130.尿素窒素判定
+	cRec[130] = inRec[124]
 
-				// 167.医師の診断(判定)
-				cRec[167] = inRecs[J][136]
+	// 131.尿ウロビリノーゲン判定
+	cRec[131] = inRec[125]
 
-				// 168.健康診断を実施した医師の氏名
-				cRec[168] = "寺門　節雄"
+	// 132.ヘマトクリット値判定
+	cRec[132] = inRec[126]
 
-				// 169.医師の意見
+	// 133.血色素量(ヘモグロビン値)判定
+	cRec[133] = inRec[127]
 
-				// 170.意見を述べた医師の氏名
+	// 134.赤血球数判定
+	cRec[134] = inRec[128]
 
-				// 171.歯科医師による健康診断
+	// 135.白血球数判定
+	cRec[135] = inRec[129]
 
-				// 172.歯科医師による健康診断を実施した歯科医師の氏名
+	// 136.血小板数判定
+	cRec[136] = inRec[130]
 
-				// 173.歯科医師の意見
+	// 137.視力(右)判定
+	cRec[137] = eyeHantei(inRec[131], inRec[132])
 
-				// 174.意見を述べた歯科医師の氏名
+	// 138.視力(左)判定
+	cRec[138] = eyeHantei(inRec[133], inRec[134])
 
-				// 175.備考
+	// 139～148.追加項目判定1～10（tsuikaSetで設定）
 
-				// 176.服薬１_血圧
-				cRec[176] = inRecs[J][139]
+	// 149.コメント
 
-				// 177.血圧_薬剤
+	// 150.総合判定
+	if inRec[135] == "" {
+		log.Print("総合判定が抜けている方がいます。")
+	}
+	cRec[150] = inRec[135]
 
-				// 178.血圧_服薬理由
+	// 151.受診勧奨区分
 
-				// 179.服薬２_血糖
-				cRec[179] = inRecs[J][140]
+	// 152.指導状態
 
-				// 180.血糖_薬剤
+	// 153.再検査区分
 
-				// 181.血糖_服薬理由
+	// 154.一次健診日
 
-				// 182.服薬３_脂質
-				cRec[182] = inRecs[J][141]
+	// 155.結果通知区分
 
-				// 183.脂質_薬剤
+	// 156.メタボリック判定(血圧リスク)
 
-				// 184.脂質_服薬理由
+	// 157.メタボリック判定(血糖リスク)
 
-				// 185.既往歴１_脳血管
-				cRec[185] = inRecs[J][142]
+	// 158.メタボリック判定(脂質リスク)
 
-				// 186.既往歴２_心血管
-				cRec[186] = inRecs[J][143]
+	// 159.メタボリック判定(リスクカウント)
 
-				// 187.既往歴３_腎不全人工透析
-				cRec[187] = inRecs[J][144]
+	// 160.支援レベル(血圧リスク)
 
-				// 188.貧血
-				cRec[188] = inRecs[J][145]
+	// 161.支援レベル(血糖リスク)
 
-				// 189.喫煙
-				cRec[189] = inRecs[J][146]
+	// 162.支援レベル(脂質リスク)
 
-				// 190.２０歳からの体重変化
-				cRec[190] = inRecs[J][147]
+	// 163.支援レベル(喫煙リスク)
 
-				// 191.３０分以上の運動習慣
-				cRec[191] = inRecs[J][148]
+	// 164.支援レベル(リスクカウント)
 
-				// 192.歩行又は身体活動
-				cRec[192] = inRecs[J][149]
+	// 165.メタボリックシンドローム判定
+	cRec[165] = inRec[137]
 
-				// 193.歩行速度
-				cRec[193] = inRecs[J][150]
+	// 166.支援レベル
+	cRec[166] = inRec[138]
 
-				// 194.１年間の体重変化
-				//cRec[194] = inRecs[J][151]
+	// 167.医師の診断(判定)
+	cRec[167] = inRec[136]
 
-				// 195.食事についての咀嚼
-				cRec[195] = inRecs[J][151]
+	// 168.健康診断を実施した医師の氏名
+	cRec[168] = "寺門　節雄"
 
-				// 196.食べ方１_早食い等
-				cRec[196] = inRecs[J][152]
+	// 169.医師の意見
 
-				// 197.食べ方２_就寝前
-				cRec[197] = inRecs[J][153]
+	// 170.意見を述べた医師の氏名
 
-				// 198.食べ方３_夜食間食
-				//cRec[198] = inRecs[J][154]
+	// 171.歯科医師による健康診断
 
-				// 199.食べ方３_三食以外の間食
-				cRec[199] = inRecs[J][154]
+	// 172.歯科医師による健康診断を実施した歯科医師の氏名
 
-				// 200.食習慣
-				cRec[200] = inRecs[J][155]
+	// 173.歯科医師の意見
 
-				// 201.飲酒
-				cRec[201] = inRecs[J][156]
+	// 174.意見を述べた歯科医師の氏名
 
-				// 202.飲酒量
-				cRec[202] = inRecs[J][157]
+	// 175.備考
 
-				// 203.睡眠
-				cRec[203] = inRecs[J][158]
+	// 176～205.問診（受診日の版の質問票で回答コードにする。177・178・180・181・183・184は空欄）
+	monshinSet(cRec, inRec)
 
-				// 204.生活習慣の改善
-				cRec[204] = inRecs[J][159]
+	// 185～187.既往歴１～３（問診が空欄なら既往歴から判定する）
+	cRec[185] = kiouFlag(cRec[185], "脳血管", kiouCodes, inRec[1])
+	cRec[186] = kiouFlag(cRec[186], "心血管", kiouCodes, inRec[1])
+	cRec[187] = kiouFlag(cRec[187], "腎不全", kiouCodes, inRec[1])
 
-				// 205.保健指導の希望
-				cRec[205] = inRecs[J][160]
+	// 206.報告対象区分
 
-				// 206.報告対象区分
+	// 207.保健指導からの除外
 
-				// 207.保健指導からの除外
+	// 208.取込年月日
 
-				// 208.取込年月日
+	// 209.胸部X線判定①（一次読影）
+	cRec[209] = xray1
 
-				// 209.胸部X線判定①
-				cRec[209] = string(norm.NFKC.Bytes([]byte(inRecs[J][82])))
+	// 210.胸部X線判定②（二次読影）
+	cRec[210] = xray2
 
-				// 210.胸部X線判定②
-				cRec[210] = string(norm.NFKC.Bytes([]byte(inRecs[J][82])))
+	// 211.心電図判定
+	cRec[211] = string(norm.NFKC.Bytes([]byte(inRec[77])))
 
-				// 211.心電図判定
-				cRec[211] = string(norm.NFKC.Bytes([]byte(inRecs[J][77])))
+	// 212.胸部レントゲン検査（二次読影後の判定。二次読影をしていなければ一次読影の判定）
+	xray := xray2
+	if xray == "" {
+		xray = xray1
+	}
+	cRec[212] = hanteiCode(xray)
 
-				// 212.胸部レントゲン検査
-				cRec[212] = hanteiCode(string(norm.NFKC.Bytes([]byte(inRecs[J][82]))))
+	// 213.胸部レントゲン判定（212と同じ）
+	cRec[213] = hanteiCode(xray)
 
-				// 213.胸部レントゲン判定
-				cRec[213] = hanteiCode(string(norm.NFKC.Bytes([]byte(inRecs[J][82]))))
+	// 214.尿糖
+	cRec[214] = nyou(inRec[66])
 
-				// 214.尿糖
-				cRec[214] = nyou(inRecs[J][66])
+	// 215.尿蛋白
+	cRec[215] = nyou(inRec[67])
 
-				// 215.尿蛋白
-				cRec[215] = nyou(inRecs[J][67])
+	// 216.聴力(右1000Hz)
+	cRec[216] = syokenumuCode(syokenumu1k(inRec[99]))
 
-				// 216.聴力(右1000Hz)
-				cRec[216] = syokenumuCode(syokenumu(inRecs[J][99]))
+	// 217.聴力(右4000Hz)
+	cRec[217] = syokenumuCode(syokenumu4k(inRec[101], inRec[103]))
 
-				// 217.聴力(右4000Hz)
-				cRec[217] = syokenumuCode(syokenumu4k(inRecs[J][101], inRecs[J][103]))
+	// 218.聴力(左1000Hz)
+	cRec[218] = syokenumuCode(syokenumu1k(inRec[100]))
 
-				// 218.聴力(左1000Hz)
-				cRec[218] = syokenumuCode(syokenumu(inRecs[J][100]))
+	// 219.聴力(左4000Hz)
+	cRec[219] = syokenumuCode(syokenumu4k(inRec[102], inRec[104]))
 
-				// 219.聴力(左4000Hz)
-				cRec[219] = syokenumuCode(syokenumu4k(inRecs[J][102], inRecs[J][104]))
+	// 220.心電図検査
+	cRec[220] = hanteiCode(string(norm.NFKC.Bytes([]byte(inRec[77]))))
 
-				// 220.心電図検査
-				cRec[220] = hanteiCode(string(norm.NFKC.Bytes([]byte(inRecs[J][77]))))
+	// 221.心電図判定
+	cRec[221] = hanteiCode(string(norm.NFKC.Bytes([]byte(inRec[77]))))
 
-				// 221.心電図判定
-				cRec[221] = hanteiCode(string(norm.NFKC.Bytes([]byte(inRecs[J][77]))))
+	// 詳細な健診の実施理由
+	syousaiSet(cRec, inRec, kiouCodes)
 
-				//writer.Write(cRec)
-				row = sheet.AddRow()
-				for _, cell = range cRec {
-					// sheet.Cell(r, c).Value = cell
-					vcell = row.AddCell()
-					vcell.Value = cell
-				}
-				r++
-			}
-		}
-
-		//writer.Flush()
-		err = excelFile.Save(excelName)
-		failOnError(err)
-	}
+	return cRec, kiouCodes
+}
 
+// 健保組合へ提出する1人分の健診データ（文字数の確認だけをする）
+func kenpoRec(cRec []string, head []string) []string {
+	mojisuCheck(cRec, head, cRec[2])
+	return cRec
 }
 
 func meiboCreate(filename string, inRecs [][]string, coRecs [][]string) {
@@ -1233,8 +1296,11 @@ func meiboCreate(filename string, inRecs [][]string, coRecs [][]string) {
 		failOnError(err)
 
 		r := 0
+		meiboHead := make([][]string, 0)
+		meibo := make([][]string, 0)
 		for _, inRec := range inRecs {
-			if (coRec[0] == inRec[4] && coseCheck(inRec[13])) || inRec[4] == "所属cd１" {
+			// 要確認の方は健診データに載せないので、受診者名簿にも載せない
+			if (outCheck(inRec, coRec[0]) && honninCheck(inRec) && youkakunin[inRec[1]] == "") || inRec[4] == "所属cd１" {
 				jRec[0] = inRec[4]
 				jRec[1] = inRec[5]
 				jRec[2] = inRec[6]
@@ -1257,6 +1323,11 @@ func meiboCreate(filename string, inRecs [][]string, coRecs [][]string) {
 					vcell.Value = cell
 
 				}
+				if inRec[4] == "所属cd１" {
+					meiboHead = append(meiboHead, append([]string{}, jRec...))
+				} else {
+					meibo = append(meibo, append([]string{}, jRec...))
+				}
 				r++
 			}
 
@@ -1265,6 +1336,8 @@ func meiboCreate(filename string, inRecs [][]string, coRecs [][]string) {
 		//writer.Flush()
 		err = excelFile.Save(excelName)
 		failOnError(err)
+
+		kensyou(excelName, meiboHead, meibo, nil, coRec[0], "")
 	}
 
 }
@@ -1301,6 +1374,47 @@ func WaToSeireki(nen string) string {
 	}
 }
 
+// 日付を yyyy/mm/dd にそろえる（yyyy-mm-dd, yyyy/m/d, yyyymmdd を受け付ける。読めなければ空欄）
+func dateSlash(s string) string {
+	s = strings.TrimSpace(string(norm.NFKC.Bytes([]byte(s))))
+	for _, layout := range []string{"2006-01-02", "2006/01/02", "2006-1-2", "2006/1/2", "20060102"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("2006/01/02")
+		}
+	}
+	return ""
+}
+
+// 胸部X線の撮影年月日（巡回健診などで受診日と違う日に撮影した場合は入力の撮影日を使う）
+// 撮影日の項目がない入力ファイル（A85パターンの旧版）は、これまでどおり受診日とする
+// 撮影日の項目があるのに空欄・日付でない場合は推測せず、記録して書式の確認で止める
+func satsueibi(inRec []string, kekka bool) string {
+	if !inHas("胸部X線撮影日", "撮影年月日") {
+		if !kekka {
+			return ""
+		}
+		return strings.Replace(inRec[15], "-", "/", -1)
+	}
+
+	s := inVal(inRec, "胸部X線撮影日", "撮影年月日")
+	if s == "" {
+		if kekka {
+			log.Printf("Error:胸部X線の結果がありますが撮影年月日がありません 受診者ID:%v\r\n", inRec[1])
+		}
+		return ""
+	}
+
+	if !kekka {
+		log.Printf("胸部X線の撮影年月日がありますが結果がありません 受診者ID:%v\r\n", inRec[1])
+	}
+	d := dateSlash(s)
+	if d == "" {
+		log.Printf("Error:胸部X線の撮影年月日が日付ではありません 受診者ID:%v 撮影年月日:%v\r\n", inRec[1], s)
+		return s
+	}
+	return d
+}
+
 func nendo(JDay string) string {
 	var nen int
 	t, _ := time.Parse("2006-01-02", JDay)
@@ -1359,18 +1473,69 @@ func cutStrings(s string, maxLen int) string {
 
 func syoken(s string) string {
 	s = strings.Replace(s, "　", " ", -1)
+	s = dsTrim(s)
 	s = strings.Trim(s, " ")
 
-	for {
-		if utf8.RuneCountInString(s) > 25 {
-			pos := strings.LastIndex(s, " ")
-			s = s[:pos]
-		} else {
-			break
+	return s
+}
+
+// 文字数を超えた所見はスペースの位置で区切り、残りを返す
+func syokenCut(s string, maxLen int) (string, string) {
+	if utf8.RuneCountInString(s) <= maxLen {
+		return s, ""
+	}
+
+	r := []rune(s)
+	pos := strings.LastIndex(string(r[:maxLen+1]), " ")
+	if pos <= 0 {
+		return string(r[:maxLen]), strings.Trim(string(r[maxLen:]), " ")
+	}
+	return s[:pos], strings.Trim(s[pos:], " ")
+}
+
+// 列の最大文字数（文字数の設定にない列は0）
+func mojisuMax(c int) int {
+	for _, m := range mojisu {
+		if m[0] == strconv.Itoa(c) {
+			maxLen, _ := strconv.Atoi(m[1])
+			return maxLen
 		}
 	}
+	return 0
+}
 
-	return s
+// 文字数制限を超えた項目は、超えた分を設定した列（備考・コメント）へ移して記録する
+// 移した先の列も最大文字数を超えていないか確かめる
+func mojisuCheck(cRec []string, head []string, id string) {
+	for _, m := range mojisu {
+		c, err1 := strconv.Atoi(m[0])
+		maxLen, err2 := strconv.Atoi(m[1])
+		if err1 != nil || err2 != nil || c >= len(cRec) {
+			log.Printf("文字数の設定が正しくありません:%v\r\n", m)
+			continue
+		}
+		if m[2] == "" {
+			continue
+		}
+		to, err := strconv.Atoi(m[2])
+		if err != nil || to >= len(cRec) {
+			log.Printf("文字数の設定が正しくありません:%v\r\n", m)
+			continue
+		}
+
+		s, over := syokenCut(cRec[c], maxLen)
+		if over == "" {
+			continue
+		}
+
+		cRec[c] = s
+		cRec[to] = strings.Trim(cRec[to]+" "+head[c]+":"+over, " ")
+		log.Printf("文字数超過のため%vへ移しました 受診者ID:%v %v:%v\r\n", head[to], id, head[c], over)
+
+		if toMax := mojisuMax(to); toMax > 0 && utf8.RuneCountInString(cRec[to]) > toMax {
+			log.Printf("Error:移した先の%vも%v文字を超えています 受診者ID:%v\r\n", head[to], toMax, id)
+		}
+	}
 }
 
 func nyou(s string) string {
@@ -1455,10 +1620,37 @@ func syokenumu(s string) string {
 	return s
 }
 
+// 聴力は判定記号(A～G)、オージオメータのdB値、所見なし/所見ありの記載のいずれでも受け付ける
+// dB値は1000Hzが30dB、4000Hzが40dBまで聞こえれば所見なしとする
+func chouryoku(s string, limit float64) string {
+	s = strings.TrimSpace(string(norm.NFKC.Bytes([]byte(s))))
+
+	db := strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(s, "dB"), "db"))
+	if v, err := strconv.ParseFloat(db, 64); err == nil {
+		if v <= limit {
+			return "所見なし"
+		}
+		return "所見あり"
+	}
+
+	switch s {
+	case "所見なし", "異常なし":
+		return "所見なし"
+	case "所見あり", "異常あり":
+		return "所見あり"
+	}
+
+	return syokenumu(s)
+}
+
+func syokenumu1k(s string) string {
+	return chouryoku(s, 30)
+}
+
 func syokenumu4k(s1, s2 string) string {
 	var s string
-	s1s := syokenumu(s1)
-	s2s := syokenumu(s2)
+	s1s := chouryoku(s1, 40)
+	s2s := chouryoku(s2, 40)
 
 	s = s1s
 	if s == "" {
@@ -1468,6 +1660,18 @@ func syokenumu4k(s1, s2 string) string {
 	return s
 }
 
+// 聴力の検査方法とその他の所見
+func chouryokuSonota(inRec []string) string {
+	houhou := string(norm.NFKC.Bytes([]byte(inVal(inRec, "聴力検査方法"))))
+	if strings.Contains(houhou, "オージオ") {
+		houhou = "オージオメータ"
+	} else if strings.Contains(houhou, "選別") {
+		houhou = "選別聴力検査"
+	}
+
+	return syoken(houhou + " " + inVal(inRec, "聴力その他所見", "聴力その他の所見"))
+}
+
 func syokenumuCode(s string) string {
 
 	if s == "所見なし" {
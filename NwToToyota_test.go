@@ -0,0 +1,88 @@
+package main
+
+import "testing"
+
+// 聴力は判定記号・dB値・所見の記載のいずれでも受け付ける
+func TestChouryoku(t *testing.T) {
+	tests := []struct {
+		s     string
+		limit float64
+		want  string
+	}{
+		{"", 30, ""},
+		{"30", 30, "所見なし"},
+		{"31", 30, "所見あり"},
+		{"25dB", 30, "所見なし"},
+		{"４５ｄＢ", 40, "所見あり"},
+		{"40", 40, "所見なし"},
+		{"所見なし", 30, "所見なし"},
+		{"異常あり", 30, "所見あり"},
+		{"A", 30, "所見なし"},
+		{"C", 30, "所見あり"},
+		{"不明", 30, "err"},
+	}
+	for _, tt := range tests {
+		if got := chouryoku(tt.s, tt.limit); got != tt.want {
+			t.Errorf("chouryoku(%q, %v) = %q, want %q", tt.s, tt.limit, got, tt.want)
+		}
+	}
+}
+
+// 文字数を超えた所見はスペースの位置で区切る（スペースがなければ文字数で切る）
+func TestSyokenCut(t *testing.T) {
+	tests := []struct {
+		s          string
+		maxLen     int
+		want, over string
+	}{
+		{"あいう", 5, "あいう", ""},
+		{"あいうえお", 5, "あいうえお", ""},
+		{"あいう えお かき", 5, "あいう", "えお かき"},
+		{"あいうえお かき", 5, "あいうえお", "かき"},
+		{"あいうえおかき", 5, "あいうえお", "かき"},
+		{"慢性腎臓病 狭心症治療中 ヘルペス", 12, "慢性腎臓病 狭心症治療中", "ヘルペス"},
+	}
+	for _, tt := range tests {
+		got, over := syokenCut(tt.s, tt.maxLen)
+		if got != tt.want || over != tt.over {
+			t.Errorf("syokenCut(%q, %v) = %q, %q, want %q, %q", tt.s, tt.maxLen, got, over, tt.want, tt.over)
+		}
+	}
+}
+
+// 撮影年月日は撮影日の項目があればその値、なければ結果のある方の受診日
+func TestSatsueibi(t *testing.T) {
+	rec := func(satsuei string) []string {
+		r := make([]string, 17)
+		r[1] = "ID0001"
+		r[15] = "2021-06-01"
+		r[16] = satsuei
+		return r
+	}
+	tests := []struct {
+		name  string
+		kou   bool // 撮影日の項目がある
+		inRec []string
+		kekka bool
+		want  string
+	}{
+		{"項目なし・結果あり", false, rec(""), true, "2021/06/01"},
+		{"項目なし・結果なし", false, rec(""), false, ""},
+		{"撮影日あり", true, rec("2021-05-20"), true, "2021/05/20"},
+		{"撮影日の表記ゆれ", true, rec("２０２１/５/２０"), true, "2021/05/20"},
+		{"撮影日が空欄", true, rec(""), true, ""},
+		{"撮影日が日付でない", true, rec("不明"), true, "不明"},
+	}
+	for _, tt := range tests {
+		inHead = map[string]int{}
+		head := make([]string, 17)
+		if tt.kou {
+			head[16] = "胸部X線撮影日"
+		}
+		inHeadSet(head)
+		if got := satsueibi(tt.inRec, tt.kekka); got != tt.want {
+			t.Errorf("%v: satsueibi = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	inHead = map[string]int{}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+// 設定ファイルは実行ファイルと同じ場所の「設定」フォルダに置く
+// 形式はタブ区切り（Shift-JIS）、1行目は項目名、#で始まる行はコメント
+// ファイルがなければプログラム内の既定値を使う
+
+// 文字数制限（列, 最大文字数, 超えた分の移動先列）
+// 移動先列が空欄の列は移す先がないので、超えていれば記録する（書式の確認で止まる）
+var mojisu = [][]string{
+	{"33", "25", "175"}, // 既往歴 → 備考
+	{"34", "25", "149"}, // 自覚症状 → コメント
+	{"35", "25", "149"}, // 他覚症状 → コメント
+	{"70", "25", "149"}, // 心電図(所見) → コメント
+	{"72", "25", "149"}, // 胸部X線検査(所見) → コメント
+	{"149", "200", ""},  // コメント
+	{"175", "200", ""},  // 備考
+}
+
+func confLoad() {
+	kaisya = readConf("会社マスタ", kaisya, 4)
+	syainRule = readConf("社員番号ルール", syainRule, 5)
+	mojisu = readConf("文字数", mojisu, 3)
+	kiouJisyo = readConf("既往歴辞書", kiouJisyo, 3)
+	syojyouJisyo = readConf("症状辞書", syojyouJisyo, 3)
+	ecgJisyo = readConf("心電図辞書", ecgJisyo, 3)
+	xrayJisyo = readConf("胸部X線辞書", xrayJisyo, 3)
+	tsuikaKoumoku = readConf("追加項目", tsuikaKoumoku, 5)
+	koumokuSeigen = readConf("項目制限", koumokuSeigen, 4)
+	douisya = readConf("同意者", douisya, 3)
+	teikyouJogai = readConf("提供除外", teikyouJogai, 4)
+	yusyoken = readConf("有所見基準", yusyoken, 4)
+	keinenKoumoku = readConf("経年項目", keinenKoumoku, 4)
+	kensyouKoumoku = readConf("検証項目", kensyouKoumoku, 2)
+	syoshiki = readConf("書式", syoshiki, 7)
+	monshin = readConf("問診", monshin, 5)
+}
+
+func confDir() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return "./設定/"
+	}
+	return filepath.Dir(exe) + "/設定/"
+}
+
+// colsは設定の項目数（足りない行は空欄で埋める）
+func readConf(name string, defs [][]string, cols int) [][]string {
+	filename := confDir() + name + ".txt"
+	infile, err := os.Open(filename)
+	if err != nil {
+		return defs
+	}
+	defer infile.Close()
+
+	reader := csv.NewReader(transform.NewReader(infile, japanese.ShiftJIS.NewDecoder()))
+	reader.Comma = '\t'
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	conf := make([][]string, 0)
+	colMax := cols
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else {
+			failOnError(err)
+		}
+
+		if len(record) > colMax {
+			colMax = len(record)
+		}
+		conf = append(conf, record)
+	}
+
+	// 項目が足りない行は空欄で埋める
+	for i := range conf {
+		for len(conf[i]) < colMax {
+			conf[i] = append(conf[i], "")
+		}
+	}
+
+	log.Print("設定ファイルを読み込みました:" + filename + "\r\n")
+	if len(conf) == 0 {
+		return conf
+	}
+	return conf[1:]
+}
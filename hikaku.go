@@ -0,0 +1,205 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tealeg/xlsx"
+	"golang.org/x/text/unicode/norm"
+)
+
+// 出力の比較（-hikaku 前のファイル 後のファイル）
+// このプログラムで作った健診データか受診者名簿を2つ読み、社員番号と受診日で行を合わせて
+// 追加・削除された行と、項目名毎に変わった値を「比較結果」に書き出す（変わったセルは色を付ける）
+var hikakuMode = flag.Bool("hikaku", false, "出力した健診データ・受診者名簿を2つ比べる")
+
+// 比較するファイルの中身（項目名と、キー毎の行）
+type hikakuFile struct {
+	head []string
+	keys []string
+	rows map[string][]string
+}
+
+// 項目名の行は先頭3行のうち、受診日と社員番号（従業員番号）のある行
+func hikakuRead(filename string) hikakuFile {
+	sheets, err := xlsx.FileToSlice(filename)
+	failOnError(err)
+	if len(sheets) == 0 {
+		log.Fatal("Error:シートがありません:" + filename)
+	}
+	rows := sheets[0]
+
+	start := -1
+	var col map[string]int
+	for i := 0; i < len(rows) && i < 3; i++ {
+		c := koumokuCol(rows[i])
+		_, jusinbi := c["受診日"]
+		_, syain := c["社員番号"]
+		_, jyugyouin := c["#従業員番号"]
+		if jusinbi && (syain || jyugyouin) {
+			start, col = i, c
+		}
+	}
+	// 受診者名簿は1行目がA85パターンの項目名なので、社員番号・受診日は列の位置で決める
+	if start < 0 && len(rows) > 0 && len(rows[0]) >= 14 && rows[0][0] == "所属cd１" {
+		rows[0][6], rows[0][12] = "社員番号", "受診日"
+		start, col = 0, koumokuCol(rows[0])
+	}
+	if start < 0 {
+		log.Fatal("Error:社員番号・受診日の項目がありません:" + filename)
+	}
+
+	// 同じ項目名が2つ目からは(2)を付ける
+	f := hikakuFile{rows: map[string][]string{}}
+	kazu := map[string]int{}
+	for _, h := range rows[start] {
+		h = string(norm.NFKC.Bytes([]byte(strings.TrimSpace(h))))
+		kazu[h]++
+		if kazu[h] > 1 {
+			h = fmt.Sprintf("%v(%v)", h, kazu[h])
+		}
+		f.head = append(f.head, h)
+	}
+
+	val := func(row []string, name string) string {
+		if i, ok := col[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+	for _, row := range rows[start+1:] {
+		syain := val(row, "社員番号")
+		if syain == "" {
+			syain = val(row, "#従業員番号")
+		}
+		if syain == "" {
+			syain = "ID:" + val(row, "受診者ID")
+		}
+		key := syain + "\t" + strings.Replace(val(row, "受診日"), "-", "/", -1)
+		// 同じ社員番号・受診日が複数あれば出てきた順に番号を付ける
+		k := key
+		for n := 2; f.rows[k] != nil; n++ {
+			k = fmt.Sprintf("%v\t%v", key, n)
+		}
+		f.keys = append(f.keys, k)
+		f.rows[k] = row
+	}
+	return f
+}
+
+func hikakuCreate(mae, ato string) {
+	m := hikakuRead(mae)
+	a := hikakuRead(ato)
+
+	// 比べる項目（後のファイルの順に、前のファイルにしかない項目を後ろに足す）
+	koumoku := append([]string{}, a.head...)
+	ari := map[string]bool{}
+	for _, h := range a.head {
+		ari[h] = true
+	}
+	for _, h := range m.head {
+		if !ari[h] {
+			koumoku = append(koumoku, h)
+			log.Print("後のファイルにない項目です:" + h + "\r\n")
+		}
+	}
+	idx := func(head []string) map[string]int {
+		c := map[string]int{}
+		for i, h := range head {
+			c[h] = i
+		}
+		return c
+	}
+	mcol, acol := idx(m.head), idx(a.head)
+	atai := func(row []string, col map[string]int, h string) string {
+		if i, ok := col[h]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+	keyRec := func(k string) []string {
+		s := strings.Split(k, "\t")
+		return []string{s[0], s[1]}
+	}
+
+	tsuika := [][]string{append([]string{"社員番号", "受診日"}, a.head...)}
+	sakujyo := [][]string{append([]string{"社員番号", "受診日"}, m.head...)}
+	ichiran := [][]string{{"社員番号", "受診日", "項目名", "前の値", "後の値"}}
+	henkou := make([][]string, 0)
+	henkouCell := make([][]bool, 0)
+
+	for _, k := range a.keys {
+		row := a.rows[k]
+		mrow, ok := m.rows[k]
+		if !ok {
+			tsuika = append(tsuika, append(keyRec(k), row...))
+			continue
+		}
+		rec := keyRec(k)
+		cells := []bool{false, false}
+		kawatta := false
+		for _, h := range koumoku {
+			mv, av := atai(mrow, mcol, h), atai(row, acol, h)
+			rec = append(rec, av)
+			cells = append(cells, mv != av)
+			if mv != av {
+				kawatta = true
+				ichiran = append(ichiran, append(keyRec(k), h, mv, av))
+			}
+		}
+		if kawatta {
+			henkou = append(henkou, rec)
+			henkouCell = append(henkouCell, cells)
+		}
+	}
+	for _, k := range m.keys {
+		if _, ok := a.rows[k]; !ok {
+			sakujyo = append(sakujyo, append(keyRec(k), m.rows[k]...))
+		}
+	}
+
+	excelFile := xlsx.NewFile()
+	xlsx.SetDefaultFont(11, "ＭＳ Ｐゴシック")
+	addSheet := func(name string, rows [][]string, iro [][]bool) {
+		sheet, err := excelFile.AddSheet(name)
+		failOnError(err)
+		style := xlsx.NewStyle()
+		style.Fill = *xlsx.NewFill("solid", "FFFFEB9C", "FFFFEB9C")
+		style.ApplyFill = true
+		for r, rec := range rows {
+			row := sheet.AddRow()
+			for c, v := range rec {
+				cell := row.AddCell()
+				cell.Value = v
+				if iro != nil && r > 0 && iro[r-1][c] {
+					cell.SetStyle(style)
+				}
+			}
+		}
+	}
+
+	addSheet("概要", [][]string{
+		{"前のファイル", mae},
+		{"後のファイル", ato},
+		{"前の件数", fmt.Sprint(len(m.keys))},
+		{"後の件数", fmt.Sprint(len(a.keys))},
+		{"追加", fmt.Sprint(len(tsuika) - 1)},
+		{"削除", fmt.Sprint(len(sakujyo) - 1)},
+		{"変更", fmt.Sprint(len(henkou))},
+		{"変更したセル", fmt.Sprint(len(ichiran) - 1)},
+	}, nil)
+	addSheet("追加", tsuika, nil)
+	addSheet("削除", sakujyo, nil)
+	addSheet("変更", append([][]string{append([]string{"社員番号", "受診日"}, koumoku...)}, henkou...), henkouCell)
+	addSheet("変更一覧", ichiran, nil)
+
+	day := time.Now()
+	excelName := filepath.Join(filepath.Dir(ato), "比較結果"+day.Format("20060102150405")+".xlsx")
+	err := excelFile.Save(excelName)
+	failOnError(err)
+	log.Printf("比較しました:%v 追加%v件 削除%v件 変更%v件\r\n", excelName, len(tsuika)-1, len(sakujyo)-1, len(henkou))
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tealeg/xlsx"
+	"golang.org/x/text/unicode/norm"
+)
+
+// 保険証の記号・番号を半角にし、ハイフンとスペースを取り除く
+// 長音（ー）は記号に使われることがあるので取り除かない
+func hokenNorm(s string) string {
+	s = string(norm.NFKC.Bytes([]byte(s)))
+	for _, c := range []string{"-", "‐", "‑", "‒", "–", "—", "−", " "} {
+		s = strings.Replace(s, c, "", -1)
+	}
+	return s
+}
+
+func suujiCheck(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// 保険証記号のない受診者（会社名 → 受診者ID）。会社毎にまとめて記録する
+var kigouNashi = map[string][]string{}
+var kigouNashiJun = make([]string, 0)
+
+// 保険証の番号は数字だけ、記号は空欄でないこと
+func hokenCheck(kigou, bangou, id, coName string) {
+	if kigou == "" {
+		if _, ok := kigouNashi[coName]; !ok {
+			kigouNashiJun = append(kigouNashiJun, coName)
+		}
+		kigouNashi[coName] = append(kigouNashi[coName], id)
+	}
+	if !suujiCheck(bangou) {
+		log.Printf("保険証番号が正しくありません 受診者ID:%v 番号:%v\r\n", id, bangou)
+	}
+}
+
+// 保険証記号のない受診者を会社毎に記録する
+func hokenPrint() {
+	for _, coName := range kigouNashiJun {
+		ids := kigouNashi[coName]
+		log.Printf("保険証記号がありません:%v %v件 受診者ID:%v\r\n", coName, len(ids), strings.Join(ids, ","))
+	}
+}
+
+// 保険者番号（8桁）の検証番号を確かめる
+// 法別番号から保険者別番号までの7桁に右から2,1,2…を掛け、各桁の和の下1桁を10から引いた値が検証番号
+func hokensyaBangouCheck(s string) bool {
+	if len(s) != 8 || !suujiCheck(s) {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < 7; i++ {
+		n := int(s[6-i] - '0')
+		if i%2 == 0 {
+			n = n * 2
+		}
+		sum += n/10 + n%10
+	}
+	return (10-sum%10)%10 == int(s[7]-'0')
+}
+
+// 会社マスタの組合コード・保険者番号の確認
+func hokensyaCheck(com []string) {
+	if com[3] == "" {
+		log.Printf("会社マスタに組合コードが設定されていません:%v\r\n", com[1])
+	}
+	if com[4] == "" {
+		log.Printf("会社マスタに保険者番号が設定されていません:%v\r\n", com[1])
+	} else if !hokensyaBangouCheck(com[4]) {
+		log.Printf("会社マスタの保険者番号が正しくありません:%v %v\r\n", com[1], com[4])
+	}
+}
+
+// 健保組合提出用のファイルに送付票のシートを付ける
+// 2021年形式に保険者番号の列がないため、組合コード・保険者番号は送付票に書く
+func soufuhyouSheet(excelFile *xlsx.File, coRec []string, yName string, kensu int) {
+	sheet, err := excelFile.AddSheet("送付票")
+	failOnError(err)
+
+	rows := [][]string{
+		{"会社名", coRec[1]},
+		{"組合コード", coRec[3]},
+		{"保険者番号", coRec[4]},
+		{"形式", yName + "年形式"},
+		{"件数", strconv.Itoa(kensu)},
+		{"作成日", time.Now().Format("2006/01/02")},
+	}
+	for _, rec := range rows {
+		row := sheet.AddRow()
+		for _, cell := range rec {
+			row.AddCell().Value = cell
+		}
+	}
+}
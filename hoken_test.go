@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+// 保険者番号は8桁で、8桁目が前の7桁から求めた検証番号
+func TestHokensyaBangouCheck(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"06130017", true},
+		{"06130018", false},
+		{"0613001", false},
+		{"061300170", false},
+		{"0613001A", false},
+		{"０６１３００１７", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := hokensyaBangouCheck(tt.s); got != tt.want {
+			t.Errorf("hokensyaBangouCheck(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
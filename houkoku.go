@@ -0,0 +1,190 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tealeg/xlsx"
+)
+
+// 定期健康診断結果報告書（様式第6号）の集計
+// -houkoku 20240401-20250331 で起動し、引数に集計を作成するフォルダを渡す（なければ実行ファイルの場所）
+// 履歴の本人の健診データから、会社毎・所属毎に健診項目別の実施者数と有所見者数を数える
+// 提供除外・要確認で事業者へ送付しなかった方も健康診断の実施者なので数える
+var houkokuKikan = flag.String("houkoku", "", "定期健康診断結果報告の集計期間（例：20240401-20250331）")
+
+// 有所見の基準（項目, 実施とする列, 判定の列, 有所見とする判定）
+// 列は健診データの3行目の項目名で、カンマ区切りでいずれかに値があれば実施・有所見とする
+var yusyoken = [][]string{
+	{"聴力検査（1000Hz）", "聴力(右1000Hz),聴力(左1000Hz)", "聴力(右1000Hz),聴力(左1000Hz)", "所見あり"},
+	{"聴力検査（4000Hz）", "聴力(右4000Hz),聴力(左4000Hz)", "聴力(右4000Hz),聴力(左4000Hz)", "所見あり"},
+	{"胸部X線検査", "胸部X線検査(撮影年月日),胸部X線判定①,胸部X線判定②", "胸部レントゲン判定", "2,3,4,5,6,7"},
+	{"血圧", "収縮期血圧(１回目),収縮期血圧(２回目),収縮期血圧(その他)", "血圧判定", "B,C,D,E,F,G"},
+	{"貧血検査", "血色素量(ヘモグロビン値),赤血球数", "血色素量(ヘモグロビン値)判定,赤血球数判定", "B,C,D,E,F,G"},
+	{"肝機能検査", "GOT(AST),GPT(ALT),γ-GT(γ-GTP)", "GOT(AST)判定,GPT(ALT)判定,γ-GT(γ-GTP)判定", "B,C,D,E,F,G"},
+	{"血中脂質検査", "LDLコレステロール,HDLコレステロール,中性脂肪", "LDLコレステロール判定,HDLコレステロール判定,中性脂肪判定", "B,C,D,E,F,G"},
+	{"血糖検査", "空腹時血糖,随時血糖,HbA1c(NGSP)", "空腹時血糖判定,随時血糖判定,HbA1c（NGSP)判定", "B,C,D,E,F,G"},
+	{"尿検査（糖）", "尿糖", "尿糖判定", "B,C,D,E,F,G"},
+	{"尿検査（蛋白）", "尿蛋白", "尿蛋白判定", "B,C,D,E,F,G"},
+	{"心電図検査", "心電図(所見),心電図判定", "心電図判定", "B,C,D,E,F,G"},
+}
+
+// 会社・所属毎の件数（0:実施者数 1:有所見者数、項目順。最後は健診実施者数といずれかの有所見者数）
+type houkoku struct {
+	syozoks []string
+	kensu   map[string][][]int
+}
+
+func (h *houkoku) add(syozok string, jisshi, ari []bool) {
+	if h.kensu == nil {
+		h.kensu = map[string][][]int{}
+	}
+	if syozok == "" {
+		syozok = "（所属なし）"
+	}
+	for _, s := range []string{"", syozok} {
+		k, ok := h.kensu[s]
+		if !ok {
+			k = make([][]int, len(jisshi)+1)
+			for i := range k {
+				k[i] = make([]int, 2)
+			}
+			h.kensu[s] = k
+			if s != "" {
+				h.syozoks = append(h.syozoks, s)
+			}
+		}
+
+		yu := false
+		for i := range jisshi {
+			if jisshi[i] {
+				k[i][0]++
+			}
+			if ari[i] {
+				k[i][1]++
+				yu = true
+			}
+		}
+		k[len(jisshi)][0]++
+		if yu {
+			k[len(jisshi)][1]++
+		}
+	}
+}
+
+func houkokuCreate(kikan string, args []string) {
+	k := strings.SplitN(kikan, "-", 2)
+	if len(k) != 2 || dateSlash(k[0]) == "" || dateSlash(k[1]) == "" {
+		log.Print("集計期間が正しくありません:" + kikan + "\r\n")
+		return
+	}
+	from, to := dateSlash(k[0]), dateSlash(k[1])
+
+	// 履歴の健診データ（項目の制限・提供除外の前のもの）で数える
+	rows := rirekiLoad()
+	if len(rows) < 2 {
+		log.Print("履歴がありません\r\n")
+		return
+	}
+	col := koumokuCol(rows[0])
+
+	// 同じ会社・キー・受診日が何度も履歴にあれば、後の実行のもの（訂正）を1回と数える
+	keys := make([]string, 0)
+	saisin := map[string][]string{}
+	for _, row := range rows[1:] {
+		if row[3] != "本人" {
+			continue
+		}
+		jusinbi := dateSlash(row[5])
+		if jusinbi == "" || jusinbi < from || jusinbi > to {
+			continue
+		}
+		key := row[1] + "\t" + row[4] + "\t" + jusinbi
+		if _, ok := saisin[key]; !ok {
+			keys = append(keys, key)
+		}
+		saisin[key] = row
+	}
+
+	kaisyas := make([]string, 0)
+	syukei := map[string]*houkoku{}
+	for _, key := range keys {
+		row := saisin[key]
+		kaisya := row[2]
+		if _, ok := syukei[kaisya]; !ok {
+			kaisyas = append(kaisyas, kaisya)
+			syukei[kaisya] = &houkoku{}
+		}
+
+		jisshi, ari := yusyokenCheck(row, col)
+		syozok := ""
+		if v := koumokuVal(row, col, "所属名称"); len(v) > 0 {
+			syozok = v[0]
+		}
+		syukei[kaisya].add(syozok, jisshi, ari)
+	}
+	log.Printf("履歴の健診データを集計しました:%v件\r\n", len(keys))
+
+	// 集計は引数のフォルダ（ファイルならその場所、なければ実行ファイルの場所）に作成する
+	dir := filepath.Dir(filepath.Dir(rirekiDir()))
+	if len(args) > 0 {
+		dir = args[0]
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			dir = filepath.Dir(dir)
+		}
+	}
+
+	// 会社毎のシートに、項目を行、会社計と所属を列にして書く
+	excelFile := xlsx.NewFile()
+	xlsx.SetDefaultFont(11, "ＭＳ Ｐゴシック")
+	for _, kaisya := range kaisyas {
+		h := syukei[kaisya]
+		if len(h.kensu) == 0 {
+			continue
+		}
+		sort.Strings(h.syozoks)
+		cols := append([]string{""}, h.syozoks...)
+
+		sheet, err := excelFile.AddSheet(kaisya)
+		failOnError(err)
+		addRow := func(rec ...string) {
+			row := sheet.AddRow()
+			for _, cell := range rec {
+				row.AddCell().Value = cell
+			}
+		}
+
+		addRow("定期健康診断結果報告（様式第6号）", kaisya)
+		addRow("集計期間", from+"～"+to)
+		addRow(append([]string{"項目", "区分", "会社計"}, h.syozoks...)...)
+
+		line := func(name, kubun string, i, j int) {
+			rec := []string{name, kubun}
+			for _, c := range cols {
+				rec = append(rec, fmt.Sprint(h.kensu[c][i][j]))
+			}
+			addRow(rec...)
+		}
+		line("健康診断実施者数", "", len(yusyoken), 0)
+		for i, y := range yusyoken {
+			line(y[0], "実施者数", i, 0)
+			line(y[0], "有所見者数", i, 1)
+		}
+		line("有所見者数", "いずれかの項目", len(yusyoken), 1)
+	}
+
+	if len(excelFile.Sheets) == 0 {
+		log.Print("集計期間の健診データがありません\r\n")
+		return
+	}
+
+	excelName := filepath.Join(dir, "定期健康診断結果報告集計"+strings.Replace(from, "/", "", -1)+"-"+strings.Replace(to, "/", "", -1)+".xlsx")
+	err := excelFile.Save(excelName)
+	failOnError(err)
+	log.Print("定期健康診断結果報告の集計を作成しました:" + excelName + "\r\n")
+}
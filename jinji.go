@@ -0,0 +1,272 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tealeg/xlsx"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// 会社から受け取る人事名簿（xlsxまたはCSV）は「設定/名簿」フォルダに
+// 所属cdか会社名で始まるファイル名で置く。1行目の項目名で列を探す
+var jinjiKoumoku = [][]string{
+	{"氏名", "名前", "氏名漢字"},
+	{"カナ", "フリガナ", "氏名カナ", "カナ氏名"},
+	{"生年月日"},
+	{"所属", "所属名", "所属名称", "部署"},
+	{"社員番号", "従業員番号", "社員NO"},
+}
+
+// 人事名簿の1人分
+type jinji struct {
+	name   string
+	kana   string
+	birth  string
+	syozok string
+	syain  string
+}
+
+// 異体字（照合のときだけ置き換える）
+var itaiji = strings.NewReplacer(
+	"髙", "高", "﨑", "崎", "嵜", "崎", "邊", "辺", "邉", "辺", "齋", "斉", "齊", "斉", "斎", "斉",
+	"濱", "浜", "濵", "浜", "澤", "沢", "櫻", "桜", "廣", "広", "國", "国", "眞", "真", "德", "徳",
+	"冨", "富", "嶋", "島", "嶌", "島", "槇", "槙", "惠", "恵", "黑", "黒", "瀨", "瀬", "壽", "寿",
+	"彌", "弥", "龍", "竜", "藏", "蔵", "曽", "曾",
+	" ", "", "　", "",
+)
+
+// カナは全角カタカナにし、小さい字・ヂヅを照合用にそろえる
+var kanaRep = strings.NewReplacer(
+	"ァ", "ア", "ィ", "イ", "ゥ", "ウ", "ェ", "エ", "ォ", "オ", "ッ", "ツ", "ャ", "ヤ", "ュ", "ユ", "ョ", "ヨ", "ヮ", "ワ",
+	"ヂ", "ジ", "ヅ", "ズ", " ", "", "　", "",
+)
+
+func kanaNorm(s string) string {
+	s = string(norm.NFKC.Bytes([]byte(s)))
+	r := []rune(s)
+	for i, c := range r {
+		if c >= 'ぁ' && c <= 'ゖ' {
+			r[i] = c + ('ァ' - 'ぁ')
+		}
+	}
+	return kanaRep.Replace(string(r))
+}
+
+func nameNorm(s string) string {
+	return itaiji.Replace(string(norm.NFKC.Bytes([]byte(s))))
+}
+
+// 生年月日を yyyy/mm/dd にそろえる（西暦・和暦・Excelの日付を受け付ける）
+func birthNorm(s string) string {
+	s = strings.TrimSpace(string(norm.NFKC.Bytes([]byte(s))))
+	if d := dateSlash(s); d != "" {
+		return d
+	}
+	if len(s) == 9 && strings.Contains("MTSHR", s[0:1]) {
+		if d := WaToSeireki(s); d != "err" {
+			return d
+		}
+	}
+	if t, err := time.Parse("01-02-06", s); err == nil {
+		if t.Year() > time.Now().Year() {
+			t = t.AddDate(-100, 0, 0)
+		}
+		return t.Format("2006/01/02")
+	}
+	if n, err := strconv.ParseFloat(s, 64); err == nil && n > 0 && n < 100000 {
+		return time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(n)).Format("2006/01/02")
+	}
+	return s
+}
+
+// CSVはUTF-8でなければShift-JISとして読む
+func jinjiCSV(filename string) [][]string {
+	b, err := ioutil.ReadFile(filename)
+	failOnError(err)
+
+	var r io.Reader = strings.NewReader(strings.TrimPrefix(string(b), "\uFEFF"))
+	if !utf8.Valid(b) {
+		r = transform.NewReader(strings.NewReader(string(b)), japanese.ShiftJIS.NewDecoder())
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	if strings.Contains(strings.SplitN(string(b), "\n", 2)[0], "\t") {
+		reader.Comma = '\t'
+	}
+
+	rows, err := reader.ReadAll()
+	failOnError(err)
+	return rows
+}
+
+func jinjiRead(filename string) []jinji {
+	var rows [][]string
+	if strings.EqualFold(filepath.Ext(filename), ".xlsx") {
+		sheets, err := xlsx.FileToSlice(filename)
+		failOnError(err)
+		if len(sheets) > 0 {
+			rows = sheets[0]
+		}
+	} else {
+		rows = jinjiCSV(filename)
+	}
+
+	// 項目名の行を探す（先頭から10行まで）
+	cols := make([]int, len(jinjiKoumoku))
+	start := -1
+	for i := 0; i < len(rows) && i < 10 && start < 0; i++ {
+		for k := range cols {
+			cols[k] = -1
+		}
+		for j, cell := range rows[i] {
+			cell = string(norm.NFKC.Bytes([]byte(strings.TrimSpace(cell))))
+			for k, names := range jinjiKoumoku {
+				for _, name := range names {
+					if cols[k] < 0 && cell == string(norm.NFKC.Bytes([]byte(name))) {
+						cols[k] = j
+					}
+				}
+			}
+		}
+		if cols[1] >= 0 && cols[2] >= 0 && cols[4] >= 0 {
+			start = i + 1
+		}
+	}
+	if start < 0 {
+		log.Print("人事名簿にカナ・生年月日・社員番号の項目がありません:" + filename + "\r\n")
+		return nil
+	}
+
+	val := func(row []string, k int) string {
+		if cols[k] < 0 || cols[k] >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[cols[k]])
+	}
+
+	list := make([]jinji, 0)
+	for _, row := range rows[start:] {
+		j := jinji{val(row, 0), val(row, 1), birthNorm(val(row, 2)), val(row, 3), val(row, 4)}
+		if j.kana == "" && j.syain == "" {
+			continue
+		}
+		list = append(list, j)
+	}
+	log.Printf("人事名簿を読み込みました:%v (%v人)\r\n", filename, len(list))
+	return list
+}
+
+// 会社の人事名簿（設定/名簿 フォルダの所属cdか会社名で始まるファイル）
+func jinjiLoad(coRec []string) []jinji {
+	files, err := ioutil.ReadDir(confDir() + "名簿")
+	if err != nil {
+		return nil
+	}
+
+	list := make([]jinji, 0)
+	for _, f := range files {
+		name := f.Name()
+		ext := strings.ToLower(filepath.Ext(name))
+		if f.IsDir() || (ext != ".xlsx" && ext != ".csv" && ext != ".txt") {
+			continue
+		}
+		if strings.HasPrefix(name, coRec[0]) || strings.HasPrefix(name, coRec[1]) {
+			list = append(list, jinjiRead(confDir()+"名簿/"+name)...)
+		}
+	}
+	return list
+}
+
+// カナと生年月日で名簿の候補を探し、複数なら氏名（異体字をそろえて）で絞る
+func jinjiMatch(list []jinji, name, kana, birth string) []jinji {
+	cand := make([]jinji, 0)
+	for _, j := range list {
+		if kanaNorm(j.kana) == kanaNorm(kana) && j.birth == birth {
+			cand = append(cand, j)
+		}
+	}
+
+	if len(cand) > 1 {
+		narrow := make([]jinji, 0)
+		for _, j := range cand {
+			if nameNorm(j.name) == nameNorm(name) {
+				narrow = append(narrow, j)
+			}
+		}
+		if len(narrow) > 0 {
+			cand = narrow
+		}
+	}
+	return cand
+}
+
+// 人事名簿で社員番号を補う。一人に決まれば社員番号を置き換え、決まらなければ確認一覧に載せる
+func jinjiSet(filename string, records [][]string, coRecs [][]string) {
+	rows := make([][]string, 0)
+	for _, coRec := range coRecs {
+		list := jinjiLoad(coRec)
+		if len(list) == 0 {
+			continue
+		}
+
+		syains := map[string]bool{}
+		for _, j := range list {
+			if s := syainNorm(j.syain, nil); s != "" {
+				syains[s] = true
+			}
+		}
+
+		for _, inRec := range records[1:] {
+			if inRec[4] != coRec[0] || !coseCheck(inRec[13]) || !honninCheck(inRec) {
+				continue
+			}
+
+			now := syainNorm(inRec[0], nil)
+			cand := jinjiMatch(list, inRec[9], inRec[10], WaToSeireki(inRec[11]))
+			switch {
+			case len(cand) == 1 && syainNorm(cand[0].syain, nil) != "":
+				s := syainNorm(cand[0].syain, nil)
+				if s != now {
+					log.Printf("人事名簿から社員番号を補いました 受診者ID:%v %v→%v\r\n", inRec[1], inRec[0], s)
+					inRec[0] = s
+				}
+			case now != "" && syains[now]:
+				// 社員番号が名簿にあれば、カナ・生年月日が違っても変えない
+			default:
+				riyu := "人事名簿に該当者がいません"
+				switch {
+				case len(cand) > 1:
+					riyu = "人事名簿に該当者が複数います"
+				case len(cand) == 1:
+					// 名簿の社員番号が空欄なら補えないので、受診データの社員番号のまま確認に回す
+					riyu = "人事名簿の社員番号が空欄です"
+				}
+				kouho := make([]string, 0)
+				for _, c := range cand {
+					kouho = append(kouho, c.syain+" "+c.name+" "+c.syozok)
+				}
+				rows = append(rows, []string{coRec[1], inRec[1], inRec[0], inRec[9], inRec[10], inRec[11], inRec[7], riyu, strings.Join(kouho, " / ")})
+			}
+		}
+	}
+
+	if len(rows) == 0 {
+		return
+	}
+
+	day := time.Now()
+	naibuSave(filename+"名簿照合確認（社内用）"+day.Format("20060102")+".xlsx",
+		[]string{"会社", "受診者ID", "社員番号", "氏名", "カナ", "生年月日", "所属", "理由", "候補"}, rows)
+}
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// 既往歴辞書（記載, ICD-10, 標準病名）
+// 記載は正規化した既往歴の中に含まれていれば該当とし、長い記載を優先する
+var kiouJisyo = [][]string{
+	{"高血圧", "I10", "高血圧症"},
+	{"1型糖尿病", "E10", "1型糖尿病"},
+	{"糖尿病", "E11", "糖尿病"},
+	{"耐糖能異常", "R73", "耐糖能異常"},
+	{"脂質異常", "E78", "脂質異常症"},
+	{"高脂血症", "E78", "脂質異常症"},
+	{"高コレステロール", "E78", "脂質異常症"},
+	{"高尿酸", "E79", "高尿酸血症"},
+	{"痛風", "M10", "痛風"},
+	{"脳梗塞", "I63", "脳梗塞"},
+	{"脳出血", "I61", "脳出血"},
+	{"くも膜下出血", "I60", "くも膜下出血"},
+	{"クモ膜下出血", "I60", "くも膜下出血"},
+	{"脳卒中", "I64", "脳卒中"},
+	{"一過性脳虚血", "G45", "一過性脳虚血発作"},
+	{"脳動脈瘤", "I67", "脳動脈瘤"},
+	{"狭心症", "I20", "狭心症"},
+	{"心筋梗塞", "I21", "心筋梗塞"},
+	{"虚血性心疾患", "I25", "虚血性心疾患"},
+	{"心不全", "I50", "心不全"},
+	{"心房細動", "I48", "心房細動"},
+	{"不整脈", "I49", "不整脈"},
+	{"弁膜症", "I38", "心臓弁膜症"},
+	{"慢性腎臓病", "N18", "慢性腎臓病"},
+	{"CKD", "N18", "慢性腎臓病"},
+	{"腎不全", "N19", "腎不全"},
+	{"人工透析", "Z99.2", "人工透析"},
+	{"透析", "Z99.2", "人工透析"},
+	{"腎炎", "N05", "腎炎"},
+	{"尿路結石", "N20", "尿路結石"},
+	{"腎結石", "N20", "尿路結石"},
+	{"気管支喘息", "J45", "気管支喘息"},
+	{"喘息", "J45", "気管支喘息"},
+	{"結核", "A16", "結核"},
+	{"B型肝炎", "B18.1", "B型肝炎"},
+	{"C型肝炎", "B18.2", "C型肝炎"},
+	{"脂肪肝", "K76.0", "脂肪肝"},
+	{"肝機能障害", "K76.9", "肝機能障害"},
+	{"胆石", "K80", "胆石症"},
+	{"胃潰瘍", "K25", "胃潰瘍"},
+	{"十二指腸潰瘍", "K26", "十二指腸潰瘍"},
+	{"逆流性食道炎", "K21", "逆流性食道炎"},
+	{"虫垂炎", "K37", "虫垂炎"},
+	{"甲状腺機能亢進", "E05", "甲状腺機能亢進症"},
+	{"甲状腺機能低下", "E03", "甲状腺機能低下症"},
+	{"貧血", "D64", "貧血"},
+	{"胃がん", "C16", "胃がん"},
+	{"大腸がん", "C18", "大腸がん"},
+	{"肺がん", "C34", "肺がん"},
+	{"乳がん", "C50", "乳がん"},
+	{"前立腺がん", "C61", "前立腺がん"},
+	{"子宮筋腫", "D25", "子宮筋腫"},
+	{"椎間板ヘルニア", "M51", "椎間板ヘルニア"},
+	{"うつ病", "F32", "うつ病"},
+	{"花粉症", "J30.2", "花粉症"},
+	{"アレルギー性鼻炎", "J30", "アレルギー性鼻炎"},
+	{"アトピー性皮膚炎", "L20", "アトピー性皮膚炎"},
+}
+
+// 症状辞書（記載, コード, 標準所見名）自覚症状・他覚症状に使う
+var syojyouJisyo = [][]string{
+	{"なし", "", "なし"},
+	{"特記事項なし", "", "なし"},
+	{"頭痛", "", "頭痛"},
+	{"頭重", "", "頭痛"},
+	{"めまい", "", "めまい"},
+	{"ふらつき", "", "めまい"},
+	{"動悸", "", "動悸"},
+	{"息切れ", "", "息切れ"},
+	{"胸痛", "", "胸痛"},
+	{"胸部痛", "", "胸痛"},
+	{"腹痛", "", "腹痛"},
+	{"腰痛", "", "腰痛"},
+	{"肩こり", "", "肩こり"},
+	{"咳", "", "咳"},
+	{"痰", "", "痰"},
+	{"倦怠感", "", "倦怠感"},
+	{"だるい", "", "倦怠感"},
+	{"疲れやすい", "", "倦怠感"},
+	{"不眠", "", "不眠"},
+	{"眠れない", "", "不眠"},
+	{"しびれ", "", "しびれ"},
+	{"便秘", "", "便秘"},
+	{"下痢", "", "下痢"},
+	{"胸やけ", "", "胸やけ"},
+	{"食欲不振", "", "食欲不振"},
+	{"視力低下", "", "視力低下"},
+	{"目のかすみ", "", "視力低下"},
+	{"耳鳴", "", "耳鳴り"},
+	{"難聴", "", "難聴"},
+	{"頻尿", "", "頻尿"},
+	{"むくみ", "", "浮腫"},
+	{"浮腫", "", "浮腫"},
+	{"心雑音", "", "心雑音"},
+	{"甲状腺腫大", "", "甲状腺腫大"},
+	{"貧血様", "", "貧血様"},
+	{"眼瞼結膜蒼白", "", "貧血様"},
+	{"湿疹", "", "皮疹"},
+	{"皮疹", "", "皮疹"},
+	{"肥満", "", "肥満"},
+}
+
+// 心電図所見辞書（記載, ミネソタコード, 標準所見名）
+var ecgJisyo = [][]string{
+	{"正常", "", "異常なし"},
+	{"異常なし", "", "異常なし"},
+	{"正常範囲", "", "異常なし"},
+	{"異常Q波", "1-2", "異常Q波"},
+	{"Q波", "1-3", "異常Q波"},
+	{"左軸偏位", "2-1", "左軸偏位"},
+	{"右軸偏位", "2-2", "右軸偏位"},
+	{"高電位", "3-1", "左室高電位"},
+	{"左室高電位", "3-1", "左室高電位"},
+	{"左室肥大", "3-1", "左室肥大"},
+	{"右室肥大", "3-2", "右室肥大"},
+	{"ST低下", "4-2", "ST低下"},
+	{"ST-T変化", "4-3", "ST-T異常"},
+	{"ST異常", "4-3", "ST-T異常"},
+	{"ST上昇", "9-2", "ST上昇"},
+	{"T波異常", "5-3", "T波異常"},
+	{"平低T", "5-3", "T波異常"},
+	{"陰性T", "5-2", "陰性T波"},
+	{"1度房室ブロック", "6-3", "I度房室ブロック"},
+	{"I度房室ブロック", "6-3", "I度房室ブロック"},
+	{"2度房室ブロック", "6-2", "II度房室ブロック"},
+	{"II度房室ブロック", "6-2", "II度房室ブロック"},
+	{"完全房室ブロック", "6-1", "完全房室ブロック"},
+	{"WPW", "6-4", "WPW症候群"},
+	{"PQ短縮", "6-5", "PQ短縮"},
+	{"完全左脚ブロック", "7-1", "完全左脚ブロック"},
+	{"完全右脚ブロック", "7-2", "完全右脚ブロック"},
+	{"不完全右脚ブロック", "7-3", "不完全右脚ブロック"},
+	{"右脚ブロック", "7-2", "完全右脚ブロック"},
+	{"左脚ブロック", "7-1", "完全左脚ブロック"},
+	{"心室内伝導障害", "7-4", "心室内伝導障害"},
+	{"左脚前枝ブロック", "7-7", "左脚前枝ブロック"},
+	{"上室性期外収縮", "8-1-1", "上室性期外収縮"},
+	{"心房性期外収縮", "8-1-1", "上室性期外収縮"},
+	{"心室性期外収縮", "8-1-2", "心室性期外収縮"},
+	{"期外収縮", "8-1", "期外収縮"},
+	{"心房細動", "8-3-1", "心房細動"},
+	{"心房粗動", "8-3-2", "心房粗動"},
+	{"洞性頻脈", "8-7", "洞性頻脈"},
+	{"洞性徐脈", "8-8", "洞性徐脈"},
+	{"洞性不整脈", "", "洞性不整脈"},
+	{"低電位", "9-1", "低電位"},
+	{"時計回転", "9-4-1", "時計方向回転"},
+	{"時計方向回転", "9-4-1", "時計方向回転"},
+	{"反時計回転", "9-4-2", "反時計方向回転"},
+	{"反時計方向回転", "9-4-2", "反時計方向回転"},
+	{"QT延長", "", "QT延長"},
+}
+
+// 胸部X線所見辞書（記載, コード, 標準所見名）
+var xrayJisyo = [][]string{
+	{"異常なし", "", "異常なし"},
+	{"正常", "", "異常なし"},
+	{"陳旧性胸膜炎", "", "陳旧性胸膜病変"},
+	{"胸膜肥厚", "", "胸膜肥厚"},
+	{"胸膜癒着", "", "胸膜癒着"},
+	{"石灰化", "", "石灰化影"},
+	{"陳旧性肺結核", "", "陳旧性肺結核"},
+	{"陳旧性炎症", "", "陳旧性炎症性変化"},
+	{"肺気腫", "", "肺気腫"},
+	{"肺嚢胞", "", "肺嚢胞"},
+	{"ブラ", "", "肺嚢胞"},
+	{"心拡大", "", "心拡大"},
+	{"大動脈蛇行", "", "大動脈蛇行"},
+	{"大動脈弓突出", "", "大動脈弓突出"},
+	{"大動脈石灰化", "", "大動脈石灰化"},
+	{"結節影", "", "結節影"},
+	{"腫瘤影", "", "腫瘤影"},
+	{"浸潤影", "", "浸潤影"},
+	{"線状影", "", "線状影"},
+	{"索状影", "", "線状影"},
+	{"網状影", "", "網状影"},
+	{"すりガラス", "", "すりガラス影"},
+	{"無気肺", "", "無気肺"},
+	{"気管支拡張", "", "気管支拡張"},
+	{"胸水", "", "胸水"},
+	{"横隔膜挙上", "", "横隔膜挙上"},
+	{"側弯", "", "側弯"},
+	{"術後", "", "術後変化"},
+	{"乳頭陰影", "", "乳頭陰影"},
+}
+
+// 辞書に登録のない記載（辞書名:記載 → 件数）。最後にまとめてlog.txtへ出力する
+var mitouroku = map[string]int{}
+
+// 辞書の記載と照合するため、全角半角・がんの表記・スペースをそろえる
+func jisyoNorm(s string) string {
+	s = string(norm.NFKC.Bytes([]byte(s)))
+	s = strings.Replace(s, "癌", "がん", -1)
+	s = strings.Replace(s, "ガン", "がん", -1)
+	s = strings.Replace(s, " ", "", -1)
+	return strings.ToUpper(s)
+}
+
+// 記載を含む辞書の行のうち、もっとも長い記載の行を返す
+func jisyoFind(jisyo [][]string, s string) []string {
+	var hit []string
+	ns := jisyoNorm(s)
+	if ns == "" {
+		return nil
+	}
+
+	for _, j := range jisyo {
+		key := jisyoNorm(j[0])
+		if key == "" || !strings.Contains(ns, key) {
+			continue
+		}
+		if hit == nil || len(key) > len(jisyoNorm(hit[0])) {
+			hit = j
+		}
+	}
+	return hit
+}
+
+func mitourokuAdd(name, s string) {
+	mitouroku[name+":"+s]++
+}
+
+func mitourokuPrint() {
+	if len(mitouroku) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(mitouroku))
+	for k := range mitouroku {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	log.Print("辞書に登録のない記載があります。辞書への追加を検討してください\r\n")
+	for _, k := range keys {
+		log.Print(k + " (" + fmt.Sprint(mitouroku[k]) + "件)\r\n")
+	}
+}
+
+// 所見の記載を辞書で標準所見名に置き換える（スペースで区切られた記載ごとに調べる）
+// 辞書にない記載はそのまま残し、辞書名を付けて記録する
+func syokenJisyo(jisyo [][]string, name string, fields ...string) string {
+	out := make([]string, 0)
+	for _, f := range strings.Split(syoken(strings.Join(fields, " ")), " ") {
+		if f == "" {
+			continue
+		}
+
+		// 「息切れなし」のような打ち消しは所見名に置き換えない
+		j := jisyoFind(jisyo, f)
+		if j != nil && strings.HasSuffix(jisyoNorm(f), "なし") && !strings.HasSuffix(j[2], "なし") {
+			j = nil
+		}
+
+		if j != nil {
+			f = j[2]
+		} else {
+			mitourokuAdd(name, f)
+		}
+
+		dup := false
+		for _, o := range out {
+			if o == f {
+				dup = true
+			}
+		}
+		if !dup {
+			out = append(out, f)
+		}
+	}
+
+	return strings.Join(out, " ")
+}
+
+// 既往歴（病名と治療状況）を辞書で標準病名に置き換える
+// ICD-10がjogaiで始まる病名は出力しない（事業者へ提供しない既往歴）
+// 項目の制限がある受診者（jogaiがnilでない）は、辞書にない病名も除外すべきか分からないので出力しない
+// （辞書にない記載は制限の前のデータを作るときに記録しているので、ここでは記録しない）
+// 戻り値は既往歴の文字列と、該当した病名のICD-10
+func kiouCode(inRec []string, jogai []string) (string, []string) {
+	kiou := ""
+	codes := make([]string, 0)
+	for i := 0; i < 10; i++ {
+		kp := 21 + (i * 2)
+		kiouB := kiouSet(inRec[kp])
+		kiouT := kiouSet(inRec[kp+1])
+		if kiouB == "" {
+			continue
+		}
+
+		if j := jisyoFind(kiouJisyo, kiouB); j != nil {
+			code := strings.ToUpper(strings.TrimSpace(j[1]))
+			skip := false
+			for _, jo := range jogai {
+				if strings.HasPrefix(code, jo) {
+					skip = true
+				}
+			}
+			if skip {
+				continue
+			}
+
+			kiouB = j[2]
+			codes = append(codes, code)
+		} else {
+			if jogai != nil {
+				log.Printf("項目の制限があり辞書にない既往歴のため出力しません 受診者ID:%v 既往歴:%v\r\n", inRec[1], kiouB+kiouT)
+				continue
+			}
+			mitourokuAdd("既往歴辞書", kiouB)
+		}
+
+		if kiou == "" {
+			kiou = kiouB + kiouT
+		} else {
+			kiou = kiou + " " + kiouB + kiouT
+		}
+	}
+
+	return kiou, codes
+}
+
+// ICD-10から問診の既往歴１～３の区分を求める
+func icdKubun(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	switch {
+	case code >= "I60" && code < "I70", strings.HasPrefix(code, "G45"):
+		return "脳血管"
+	case code >= "I20" && code < "I26", strings.HasPrefix(code, "I50"):
+		return "心血管"
+	case strings.HasPrefix(code, "N18"), strings.HasPrefix(code, "N19"), strings.HasPrefix(code, "Z99.2"):
+		return "腎不全"
+	}
+	return ""
+}
+
+// 問診の既往歴１～３（1:はい, 2:いいえ）。空欄で既往歴に該当する病名があれば「はい」にし、既往歴と食い違う場合は記録する
+func kiouFlag(s string, kubun string, kiouCodes []string, id string) string {
+	ari := false
+	for _, code := range kiouCodes {
+		if icdKubun(code) == kubun {
+			ari = true
+		}
+	}
+
+	// 空欄で既往歴にも病名がなければ、「いいえ」と決められないので空欄のままにする
+	if strings.TrimSpace(s) == "" {
+		if ari {
+			return "1"
+		}
+		return ""
+	}
+
+	switch yesNo(s) {
+	case "はい":
+		if !ari {
+			log.Printf("既往歴に%vの病名がありませんが問診は「はい」です 受診者ID:%v\r\n", kubun, id)
+		}
+	case "いいえ":
+		if ari {
+			log.Printf("既往歴に%vの病名がありますが問診は「いいえ」です 受診者ID:%v\r\n", kubun, id)
+		}
+	}
+	return s
+}
+
+// 問診のはい・いいえをそろえる（判別できなければ空欄）
+func yesNo(s string) string {
+	switch string(norm.NFKC.Bytes([]byte(strings.TrimSpace(s)))) {
+	case "1", "はい", "有", "あり", "有り":
+		return "はい"
+	case "2", "いいえ", "無", "なし", "無し":
+		return "いいえ"
+	}
+	return ""
+}
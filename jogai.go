@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/tealeg/xlsx"
+	"golang.org/x/text/unicode/norm"
+)
+
+// 提供除外（受診者IDまたは社員番号, 理由, 開始日, 終了日）
+// 第三者提供を拒否した人や再検査待ちなどで結果を出さない人を、期間中（空欄は期限なし）
+// 健診データと受診者名簿から除き、社内用の一覧に載せる
+var teikyouJogai = [][]string{}
+
+// 除外の理由（除外しない場合は空欄）
+func jogaiCheck(inRec []string) string {
+	today := time.Now().Format("2006/01/02")
+	for _, j := range teikyouJogai {
+		key := strings.TrimSpace(string(norm.NFKC.Bytes([]byte(j[0]))))
+		if key == "" || (key != inRec[1] && key != inRec[0]) {
+			continue
+		}
+		if from := dateSlash(j[2]); from != "" && today < from {
+			continue
+		}
+		if to := dateSlash(j[3]); to != "" && today > to {
+			continue
+		}
+
+		if j[1] == "" {
+			return "理由なし"
+		}
+		return j[1]
+	}
+	return ""
+}
+
+// 会社の出力対象か（定健コースで、提供除外でないこと）
+func outCheck(inRec []string, coCd string) bool {
+	return inRec[4] == coCd && coseCheck(inRec[13]) && jogaiCheck(inRec) == ""
+}
+
+// 社内用の一覧を作成する（Toyotaへ渡すフォルダとは別に、入力ファイルと同じ場所に置く）
+func naibuSave(excelName string, head []string, rows [][]string) {
+	excelFile := xlsx.NewFile()
+	xlsx.SetDefaultFont(11, "ＭＳ Ｐゴシック")
+	sheet, err := excelFile.AddSheet("データ")
+	failOnError(err)
+
+	for _, rec := range append([][]string{head}, rows...) {
+		row := sheet.AddRow()
+		for _, cell := range rec {
+			row.AddCell().Value = cell
+		}
+	}
+
+	err = excelFile.Save(excelName)
+	failOnError(err)
+}
+
+// 提供除外者一覧（社内用）。提供除外の方だけで出力しない会社も載せるので、会社マスタで探す
+func jogaiCreate(filename string, inRecs [][]string) {
+	rows := make([][]string, 0)
+	for _, k := range kaisya {
+		for _, inRec := range inRecs[1:] {
+			if inRec[4] != k[0] || !coseCheck(inRec[13]) {
+				continue
+			}
+			if riyu := jogaiCheck(inRec); riyu != "" {
+				rows = append(rows, []string{k[1], inRec[1], inRec[0], inRec[9], inRec[15], riyu})
+			}
+		}
+	}
+
+	if len(rows) == 0 {
+		return
+	}
+
+	day := time.Now()
+	naibuSave(filename+"提供除外者一覧（社内用）"+day.Format("20060102")+".xlsx",
+		[]string{"会社", "受診者ID", "社員番号", "氏名", "受診日", "理由"}, rows)
+}
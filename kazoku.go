@@ -0,0 +1,98 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// 特定健診として健保組合へ提出する列（それ以外は空欄にする）
+var tokuteiKoumoku = "0-42,44-52,54-59,63-66,70,71,87-94,108-111,113-121,123-128,132-134,156-168,175-207"
+
+// 続柄（A85パターンの続柄・本人家族区分、なければ枝番から判定する。どちらもなければ本人）
+// 戻り値は続柄の記載と本人かどうか
+func zokugara(inRec []string) (string, bool) {
+	zoku := string(norm.NFKC.Bytes([]byte(inVal(inRec, "続柄", "本人家族区分"))))
+	switch zoku {
+	case "":
+	case "本人", "被保険者", "1":
+		return "本人", true
+	case "家族", "被扶養者", "2":
+		return "家族", false
+	default:
+		return zoku, strings.Contains(zoku, "本人")
+	}
+
+	if eda := edaban(inRec); eda != "" {
+		if eda == "00" {
+			return "本人", true
+		}
+		return "家族", false
+	}
+
+	return "本人", true
+}
+
+func honninCheck(inRec []string) bool {
+	_, honnin := zokugara(inRec)
+	return honnin
+}
+
+// 枝番は2桁にそろえる
+func edaban(inRec []string) string {
+	eda := strings.TrimSpace(string(norm.NFKC.Bytes([]byte(inVal(inRec, "枝番")))))
+	if n, err := strconv.Atoi(eda); err == nil && len(eda) < 2 {
+		eda = "0" + strconv.Itoa(n)
+	}
+	return eda
+}
+
+// 5.続柄、6.枝番、11.扶養番号、12.受診者区分
+func zokugaraSet(cRec []string, inRec []string) {
+	zoku, honnin := zokugara(inRec)
+	cRec[5] = zoku
+	cRec[6] = edaban(inRec)
+	if honnin {
+		cRec[12] = "本人"
+	} else {
+		cRec[11] = inVal(inRec, "扶養番号")
+		if cRec[11] == "" {
+			cRec[11] = cRec[6]
+		}
+		cRec[12] = "家族"
+	}
+}
+
+// 特定健診の対象（年度末に40～74歳）か
+func tokuteiCheck(cRec []string) bool {
+	return tokuteiNenrei(cRec[16], cRec[17])
+}
+
+// 生年月日（yyyy/mm/dd）と実施年度から特定健診の対象か
+func tokuteiNenrei(seinengappi, nendo string) bool {
+	birth, err1 := time.Parse("2006/01/02", seinengappi)
+	nen, err2 := strconv.Atoi(nendo)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+
+	// 年齢は誕生日の前日に加算されるので、4月1日生まれまでは年度末に誕生日を迎えている
+	age := nen + 1 - birth.Year()
+	if birth.Month() > 4 || (birth.Month() == 4 && birth.Day() > 1) {
+		age--
+	}
+	return age >= 40 && age <= 74
+}
+
+// 特定健診の列だけを残したデータ
+func tokuteiRec(cRec []string) []string {
+	tRec := make([]string, len(cRec))
+	for _, c := range colList(tokuteiKoumoku) {
+		if c < len(cRec) {
+			tRec[c] = cRec[c]
+		}
+	}
+	return tRec
+}
@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+// 特定健診の対象は年度末（3月31日）に40～74歳。4月1日生まれは前の学年と同じく年度末に誕生日を迎える
+func TestTokuteiNenrei(t *testing.T) {
+	tests := []struct {
+		seinengappi, nendo string
+		want               bool
+	}{
+		{"1985/04/01", "2024", true},
+		{"1985/04/02", "2024", false},
+		{"1984/12/31", "2024", true},
+		{"1950/04/02", "2024", true},
+		{"1950/04/01", "2024", false},
+		{"2000/01/01", "2024", false},
+		{"", "2024", false},
+		{"1970/01/01", "", false},
+	}
+	for _, tt := range tests {
+		if got := tokuteiNenrei(tt.seinengappi, tt.nendo); got != tt.want {
+			t.Errorf("tokuteiNenrei(%q, %q) = %v, want %v", tt.seinengappi, tt.nendo, got, tt.want)
+		}
+	}
+}
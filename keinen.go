@@ -0,0 +1,184 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tealeg/xlsx"
+	"golang.org/x/text/unicode/norm"
+)
+
+// 経年変化（-keinen 年数 で、健診データに受診者毎の過去の結果を並べた「経年」シートを付ける）
+// 過去の結果は履歴フォルダから、同じ会社・キーの年度毎の最新のものを使う（送付しなかった結果は使わない）
+// 過去の結果も、その時と今回の項目の制限で事業者へ提供しない列は空欄にする
+var keinenNen = flag.Int("keinen", 0, "健診データに経年シートを付けるときの年数（例：5）")
+
+// 経年の項目（健診データ3行目の項目名, 表示名, 変化の基準, 向き）
+// 項目名はカンマ区切りで書くと値のある列の平均を使う（血圧の1回目・2回目など）
+// 前年度からの変化が基準以上なら色を付ける。向きは 増加・減少・両方
+// 判定（A～G）は記号の順で、悪くなった（後ろの記号になった）ものを増加とする
+var keinenKoumoku = [][]string{
+	{"体重", "体重", "5", "両方"},
+	{"BMI", "BMI", "2", "両方"},
+	{"収縮期血圧(１回目),収縮期血圧(２回目)", "収縮期血圧", "20", "増加"},
+	{"拡張期血圧(１回目),拡張期血圧(２回目)", "拡張期血圧", "10", "増加"},
+	{"LDLコレステロール", "LDL", "30", "増加"},
+	{"HbA1c(NGSP)", "HbA1c", "0.5", "増加"},
+	{"γ-GT(γ-GTP)", "γ-GT", "50", "増加"},
+	{"eGFR", "eGFR", "5", "減少"},
+	{"総合判定", "総合判定", "1", "増加"},
+}
+
+// 履歴（1回だけ読む。今回の実行の分は除く）
+var keinenRireki map[string][][]string
+
+// 今回の受診者の項目の制限で出力しない列（会社cd+キー）
+var keinenSeigen = map[string]string{}
+
+func keinenLoad() {
+	keinenRireki = map[string][][]string{}
+	rows := rirekiLoad()
+	if len(rows) < 2 {
+		return
+	}
+	k := len(rirekiKoumoku)
+	jyoutai := len(rows[0]) - len(rirekiKoumokuAto)
+	retsu := len(rows[0]) - 1
+	for _, row := range rows[1:] {
+		if row[0] == rirekiRunID || row[3] != "本人" || row[jyoutai] != "" {
+			continue
+		}
+		keinenRireki[row[1]+"\t"+row[4]] = append(keinenRireki[row[1]+"\t"+row[4]], keinenKesu(row[k:jyoutai], row[retsu]))
+	}
+}
+
+// 項目の制限で出力しない列を空欄にした写し
+func keinenKesu(row []string, retsu string) []string {
+	r := append([]string{}, row...)
+	for _, c := range colList(retsu) {
+		if c >= 0 && c < len(r) {
+			r[c] = ""
+		}
+	}
+	return r
+}
+
+// 値（数値、判定はA=1～G=7）。読めなければfalse
+func keinenAtai(row []string, col map[string]int, names string) (float64, string, bool) {
+	vals := koumokuVal(row, col, names)
+	if len(vals) == 0 {
+		return 0, "", false
+	}
+	sum := 0.0
+	for _, v := range vals {
+		f, ok := atof(v)
+		if !ok {
+			h := strings.ToUpper(string(norm.NFKC.Bytes([]byte(v))))
+			k := strings.Index("ABCDEFG", h)
+			if len(h) != 1 || k < 0 {
+				return 0, v, false
+			}
+			f = float64(k + 1)
+		}
+		sum += f
+	}
+	if len(vals) == 1 {
+		return sum, vals[0], true
+	}
+	f := sum / float64(len(vals))
+	return f, strconv.FormatFloat(f, 'f', 1, 64), true
+}
+
+// 前年度からの変化が基準を超えたか
+func keinenHendou(mae, ima float64, kijun, muki string) bool {
+	k, err := strconv.ParseFloat(kijun, 64)
+	if err != nil {
+		return false
+	}
+	d := ima - mae
+	switch muki {
+	case "増加":
+		return d >= k
+	case "減少":
+		return -d >= k
+	}
+	return d >= k || -d >= k
+}
+
+// 健診データのファイルに経年のシートを付ける
+func keinenSheet(excelFile *xlsx.File, coRec []string, head []string, cRecs [][]string) {
+	if keinenRireki == nil {
+		keinenLoad()
+	}
+	// 履歴は今回の健診データの列にそろえてあるので、列は今回の項目名で探す
+	col := koumokuCol(head)
+
+	sheet, err := excelFile.AddSheet("経年")
+	failOnError(err)
+
+	style := xlsx.NewStyle()
+	style.Fill = *xlsx.NewFill("solid", "FFFFC7CE", "FFFFC7CE")
+	style.ApplyFill = true
+
+	rec := []string{"社員番号", "氏名", "年度", "受診日"}
+	for _, k := range keinenKoumoku {
+		rec = append(rec, k[1])
+	}
+	row := sheet.AddRow()
+	for _, cell := range rec {
+		row.AddCell().Value = cell
+	}
+
+	hendou := 0
+	for _, cRec := range cRecs {
+		// 年度毎に最新の受診（今回の結果が優先）
+		nendos := map[string][]string{}
+		key := coRec[0] + "\t" + rirekiKey(cRec)
+		for _, r := range keinenRireki[key] {
+			r = keinenKesu(r, keinenSeigen[key])
+			n := nendo(strings.Replace(r[19], "/", "-", -1))
+			if s, ok := nendos[n]; !ok || r[19] >= s[19] {
+				nendos[n] = r
+			}
+		}
+		nendos[nendo(strings.Replace(cRec[19], "/", "-", -1))] = cRec
+
+		nens := make([]string, 0)
+		for n := range nendos {
+			nens = append(nens, n)
+		}
+		sort.Strings(nens)
+		if len(nens) > *keinenNen {
+			nens = nens[len(nens)-*keinenNen:]
+		}
+
+		mae := make([]float64, len(keinenKoumoku))
+		ari := make([]bool, len(keinenKoumoku))
+		for _, n := range nens {
+			r := nendos[n]
+			row := sheet.AddRow()
+			for _, cell := range []string{cRec[0], cRec[14], n, r[19]} {
+				row.AddCell().Value = cell
+			}
+			for i, k := range keinenKoumoku {
+				f, s, ok := keinenAtai(r, col, k[0])
+				cell := row.AddCell()
+				cell.Value = s
+				if ok && ari[i] && keinenHendou(mae[i], f, k[2], k[3]) {
+					cell.SetStyle(style)
+					hendou++
+				}
+				if ok {
+					mae[i], ari[i] = f, true
+				}
+			}
+		}
+	}
+
+	if hendou > 0 {
+		log.Printf("経年変化の大きい値に色を付けました:%v %v件\r\n", coRec[1], hendou)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// 経年表の過去の値は、その時の制限列を空欄にして出す（元の行は変えない）
+func TestKeinenKesu(t *testing.T) {
+	row := []string{"a", "b", "c", "d", "e"}
+	tests := []struct {
+		retsu string
+		want  []string
+	}{
+		{"", []string{"a", "b", "c", "d", "e"}},
+		{"1", []string{"a", "", "c", "d", "e"}},
+		{"1-3", []string{"a", "", "", "", "e"}},
+		{"0,4", []string{"", "b", "c", "d", ""}},
+		{"4,9", []string{"a", "b", "c", "d", ""}},
+	}
+	for _, tt := range tests {
+		if got := keinenKesu(row, tt.retsu); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("keinenKesu(%q) = %q, want %q", tt.retsu, got, tt.want)
+		}
+	}
+	if !reflect.DeepEqual(row, []string{"a", "b", "c", "d", "e"}) {
+		t.Errorf("元の行が変わりました: %q", row)
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/tealeg/xlsx"
+)
+
+// 出力の検証
+// 保存した健診データ・受診者名簿を保存の直後と最後に読み直し、タイトル行・件数・すべてのセルが作成した内容と
+// 同じか、設定した列が入力ファイルの値のままかを確かめる
+// 健診データは、会社・区分毎に出力するはずの受診者（読み込んだままの入力ファイルから選び直す）だけか、
+// -sabunでなければその人数がそろっているかも確かめる
+// 合わないファイルは名前の後ろに「.検証エラー」を付けて、そのまま送付できないようにし、最後にまとめて処理を止める
+
+// 入力ファイルのままのはずの列（健診データの列（2021年形式）, 入力ファイルの列）
+// 社員番号はルールでそろえ、人事名簿で補うので含めない
+var kensyouKoumoku = [][]string{
+	{"2", "1"},   // 受診者ID
+	{"7", "6"},   // 所属コード
+	{"8", "7"},   // 所属名称
+	{"13", "8"},  // 性別
+	{"14", "9"},  // 氏名漢字
+	{"18", "12"}, // 年齢
+	{"26", "16"}, // 受付NO
+}
+
+// 検証するファイル（保存した順）
+type kensyouFile struct {
+	name  string
+	head  [][]string
+	cRecs [][]string
+	cols  []int // 健診データの形式の列毎の2021年形式の列（受診者名簿はnil）
+	coCd  string
+	kubun string // 「健診データ」か「健保組合提出用」（受診者名簿は空欄）
+}
+
+var kensyouList = make([]kensyouFile, 0)
+
+// 検証の誤り（最後にまとめて止める）
+var kensyouError = make([]string, 0)
+
+// 会社・区分毎に健診データへ出力した人数
+var kensyouKensu = map[string]int{}
+
+func kensyouErr(s string) {
+	log.Print("Error:" + s + "\r\n")
+	kensyouError = append(kensyouError, s)
+}
+
+// 1ファイル分の検証（合わなければ理由）
+func kensyouCheck(f kensyouFile) string {
+	sheets, err := xlsx.FileToSlice(f.name)
+	if err != nil {
+		return "読み込めません:" + err.Error()
+	}
+	if len(sheets) == 0 {
+		return "シートがありません"
+	}
+	rows := sheets[0]
+	if len(rows) != len(f.head)+len(f.cRecs) {
+		return "行数が合いません:" + strconv.Itoa(len(rows)) + "行（" + strconv.Itoa(len(f.head)+len(f.cRecs)) + "行のはず）"
+	}
+
+	cell := func(row []string, c int) string {
+		if c < len(row) {
+			return row[c]
+		}
+		return ""
+	}
+	for r, rec := range append(append([][]string{}, f.head...), f.cRecs...) {
+		if len(rows[r]) > len(rec) {
+			return "列数が合いません:" + strconv.Itoa(r+1) + "行目"
+		}
+		for c, v := range rec {
+			if cell(rows[r], c) == v {
+				continue
+			}
+			if r < len(f.head) {
+				return "タイトル行が合いません:" + strconv.Itoa(r+1) + "行目 " + v
+			}
+			return "値が合いません:" + strconv.Itoa(r+1) + "行目 " + f.head[len(f.head)-1][c]
+		}
+	}
+
+	// 入力ファイルの値との照合（健診データのみ、受診者IDで入力の行を探す）
+	if f.cols == nil {
+		return ""
+	}
+	retsu := map[int]int{}
+	for j, c := range f.cols {
+		retsu[c] = j
+	}
+	sentaku := kensyouSentaku(f.coCd, f.kubun)
+	for r, rec := range f.cRecs {
+		id := rec[retsu[2]]
+		inRecs := kensyouSource[id]
+		if len(inRecs) == 0 {
+			return "入力ファイルにない受診者IDです:" + id
+		}
+		if sentaku[id] == 0 {
+			return "この会社・区分に出力する受診者ではありません:" + id
+		}
+		for _, k := range kensyouKoumoku {
+			c, err1 := strconv.Atoi(k[0])
+			i, err2 := strconv.Atoi(k[1])
+			j, ari := retsu[c]
+			if err1 != nil || err2 != nil || !ari {
+				continue
+			}
+			// 出力しない列（健保組合提出用の特定健診の列以外など）は確かめない
+			if rec[j] == "" {
+				continue
+			}
+			ok := false
+			for _, inRec := range inRecs {
+				if i < len(inRec) && cell(rows[len(f.head)+r], j) == inRec[i] {
+					ok = true
+				}
+			}
+			if !ok {
+				return "入力ファイルの値と合いません:" + strconv.Itoa(len(f.head)+r+1) + "行目 " + f.head[2][j]
+			}
+		}
+	}
+	return ""
+}
+
+// 入力ファイルの行（読み込んだまま。人事名簿・社員番号ルールでそろえる前の写し）
+var kensyouMoto = make([][]string, 0)
+var kensyouSource = map[string][][]string{}
+
+func kensyouSet(records [][]string) {
+	for _, k := range kensyouKoumoku {
+		_, err1 := strconv.Atoi(k[0])
+		_, err2 := strconv.Atoi(k[1])
+		if err1 != nil || err2 != nil {
+			log.Printf("検証項目の設定が正しくありません:%v\r\n", k)
+		}
+	}
+	for _, inRec := range records[1:] {
+		moto := append([]string{}, inRec...)
+		kensyouMoto = append(kensyouMoto, moto)
+		kensyouSource[moto[1]] = append(kensyouSource[moto[1]], moto)
+	}
+}
+
+// 会社・区分に出力するはずの受診者（受診者ID → 行数）
+// 提供除外は社員番号をルールでそろえた値でも探す。要確認は社員番号の確認の結果を使う
+func kensyouSentaku(coCd, kubun string) map[string]int {
+	ids := map[string]int{}
+	for _, inRec := range kensyouMoto {
+		if inRec[4] != coCd || !coseCheck(inRec[13]) {
+			continue
+		}
+		soroe := append([]string{}, inRec...)
+		soroe[0] = syainNorm(soroe[0], syainRuleFind(soroe[4]))
+		if jogaiCheck(inRec) != "" || jogaiCheck(soroe) != "" {
+			continue
+		}
+
+		honnin := honninCheck(inRec)
+		switch kubun {
+		case "健診データ":
+			if !honnin || youkakunin[inRec[1]] != "" {
+				continue
+			}
+		case "健保組合提出用":
+			if honnin && !tokuteiNenrei(WaToSeireki(inRec[11]), nendo(inRec[15])) {
+				continue
+			}
+		}
+		ids[inRec[1]]++
+	}
+	return ids
+}
+
+// 保存したファイルを検証する（合わなければ名前を変えて、最後に止める）
+func kensyou(excelName string, head [][]string, cRecs [][]string, cols []int, coCd, kubun string) {
+	f := kensyouFile{excelName, head, cRecs, cols, coCd, kubun}
+	if kubun != "" {
+		kensyouKensu[coCd+"\t"+kubun] += len(cRecs)
+	}
+	if kensyouOK(f) {
+		kensyouList = append(kensyouList, f)
+	}
+}
+
+// ファイルが検証に通ればtrue（通らなければ記録して名前を変える）
+func kensyouOK(f kensyouFile) bool {
+	riyu := kensyouCheck(f)
+	if riyu == "" {
+		return true
+	}
+	kensyouErr("出力の検証に失敗しました:" + f.name + " " + riyu)
+	if err := os.Rename(f.name, f.name+".検証エラー"); err != nil {
+		log.Print("Error:検証エラーのファイルの名前を変えられませんでした:" + err.Error() + "\r\n")
+	}
+	return false
+}
+
+// 会社・区分毎に、出力するはずの人数と健診データの行数が合うか（形式毎に分けたファイルの合計）
+func kensyouKensuCheck() {
+	for _, k := range kaisya {
+		for _, kubun := range []string{"健診データ", "健保組合提出用"} {
+			hazu := 0
+			for _, n := range kensyouSentaku(k[0], kubun) {
+				hazu += n
+			}
+			if n := kensyouKensu[k[0]+"\t"+kubun]; n != hazu {
+				kensyouErr(fmt.Sprintf("出力した人数が合いません:%v %v %v件（%v件のはず）", k[1], kubun, n, hazu))
+			}
+		}
+	}
+}
+
+// 最後に、保存した後に書き換えられていないかもう一度すべて検証し、誤りがあれば止める
+func kensyouRun() {
+	for _, f := range kensyouList {
+		kensyouOK(f)
+	}
+	if !*sabunMode {
+		kensyouKensuCheck()
+	}
+	if len(kensyouError) > 0 {
+		log.Fatalf("Error:出力の検証で%v件の誤りがありました。検証エラーのファイルは送付しないでください\r\n", len(kensyouError))
+	}
+	log.Printf("出力を検証しました %vファイル\r\n", len(kensyouList))
+}
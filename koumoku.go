@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// 健診データを項目名で扱うための共通の処理（レポート・様式第6号の集計・経年変化・比較で使う）
+
+// 3行目の項目名と列番号（同じ項目名があれば先の列）
+func koumokuCol(head []string) map[string]int {
+	col := map[string]int{}
+	for i, h := range head {
+		h = string(norm.NFKC.Bytes([]byte(strings.TrimSpace(h))))
+		if _, ok := col[h]; !ok {
+			col[h] = i
+		}
+	}
+	return col
+}
+
+// 項目名と何番目の同じ項目名かで列番号を引く（列の並びが違う健診データの行をそろえるのに使う）
+func koumokuIchi(head []string) map[string]int {
+	ichi := map[string]int{}
+	kazu := map[string]int{}
+	for i, h := range head {
+		h = string(norm.NFKC.Bytes([]byte(strings.TrimSpace(h))))
+		kazu[h]++
+		ichi[h+"\t"+strconv.Itoa(kazu[h])] = i
+	}
+	return ichi
+}
+
+// 項目名の違う順に並んだ行を、headの列の並びにそろえる（headにない列は捨て、行にない列は空欄）
+func koumokuSoroe(row []string, rowHead []string, ichi map[string]int, n int) []string {
+	out := make([]string, n)
+	kazu := map[string]int{}
+	for i, h := range rowHead {
+		h = string(norm.NFKC.Bytes([]byte(strings.TrimSpace(h))))
+		kazu[h]++
+		if j, ok := ichi[h+"\t"+strconv.Itoa(kazu[h])]; ok && i < len(row) {
+			out[j] = row[i]
+		}
+	}
+	return out
+}
+
+// 項目名（カンマ区切り）の列のうち値のあるもの
+func koumokuVal(row []string, col map[string]int, names string) []string {
+	vals := make([]string, 0)
+	for _, name := range strings.Split(names, ",") {
+		i, ok := col[string(norm.NFKC.Bytes([]byte(strings.TrimSpace(name))))]
+		if ok && i < len(row) && strings.TrimSpace(row[i]) != "" {
+			vals = append(vals, strings.TrimSpace(row[i]))
+		}
+	}
+	return vals
+}
+
+// 有所見の基準（設定/有所見基準.txt）で項目毎の実施・有所見を調べる
+func yusyokenCheck(row []string, col map[string]int) ([]bool, []bool) {
+	jisshi := make([]bool, len(yusyoken))
+	ari := make([]bool, len(yusyoken))
+	for i, y := range yusyoken {
+		jisshi[i] = len(koumokuVal(row, col, y[1])) > 0
+		for _, v := range koumokuVal(row, col, y[2]) {
+			for _, s := range strings.Split(y[3], ",") {
+				if string(norm.NFKC.Bytes([]byte(v))) == string(norm.NFKC.Bytes([]byte(strings.TrimSpace(s)))) {
+					ari[i] = true
+				}
+			}
+		}
+	}
+	return jisshi, ari
+}
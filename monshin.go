@@ -0,0 +1,251 @@
+package main
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// 問診（特定健診の標準的な質問票）
+// 受診日で質問票の版（第2期・第3期・第4期）を選び、A85パターンの問診の回答を標準の回答コードにする
+// 回答が版の選択肢にないときは記録してそのまま出力する（書式の確認で止まる）
+// 回答は表記で合わせ、番号だけの回答は版によって意味の変わる番号なら誤りにする（書式の確認で止まる）
+
+const monshinHai = "1:はい|有|あり|有り,2:いいえ|無|なし|無し"
+
+// 問診の設定（適用開始日, 出力列, 入力列, 項目名, 回答）
+// 入力列は列番号か、A85パターンに追加した項目名。出力列が空欄の質問は2021年形式に列がないので備考に書く
+// 回答は「コード:表記|別の表記,…」で、入力の表記かコードが合えばそのコードを出力する
+var monshin = [][]string{
+	// 第2期（平成20年度～）。13問目（1年間の体重変化）・16問目（夜食）は、第3期で咀嚼・間食になった問いと
+	// 同じ入力列（151・154）で、2021年形式では194・198列（検査コード061・064）に出力する
+	{"2008/04/01", "176", "139", "服薬１_血圧", monshinHai},
+	{"2008/04/01", "179", "140", "服薬２_血糖", monshinHai},
+	{"2008/04/01", "182", "141", "服薬３_脂質", monshinHai},
+	{"2008/04/01", "185", "142", "既往歴１_脳血管", monshinHai},
+	{"2008/04/01", "186", "143", "既往歴２_心血管", monshinHai},
+	{"2008/04/01", "187", "144", "既往歴３_腎不全人工透析", monshinHai},
+	{"2008/04/01", "188", "145", "貧血", monshinHai},
+	{"2008/04/01", "189", "146", "喫煙", monshinHai},
+	{"2008/04/01", "190", "147", "２０歳からの体重変化", monshinHai},
+	{"2008/04/01", "191", "148", "３０分以上の運動習慣", monshinHai},
+	{"2008/04/01", "192", "149", "歩行又は身体活動", monshinHai},
+	{"2008/04/01", "193", "150", "歩行速度", monshinHai},
+	{"2008/04/01", "194", "151", "１年間の体重変化", monshinHai},
+	{"2008/04/01", "196", "152", "食べ方１_早食い等", monshinHai},
+	{"2008/04/01", "197", "153", "食べ方２_就寝前", monshinHai},
+	{"2008/04/01", "198", "154", "食べ方３_夜食間食", monshinHai},
+	{"2008/04/01", "200", "155", "食習慣", monshinHai},
+	{"2008/04/01", "201", "156", "飲酒", "1:毎日,2:時々,3:ほとんど飲まない|飲まない|飲めない|ほとんど飲まない（飲めない）"},
+	{"2008/04/01", "202", "157", "飲酒量", "1:1合未満,2:1～2合未満,3:2～3合未満,4:3合以上"},
+	{"2008/04/01", "203", "158", "睡眠", monshinHai},
+	{"2008/04/01", "204", "159", "生活習慣の改善", "1:改善するつもりはない,2:改善するつもりである,3:近いうちに改善するつもりであり、少しずつ始めている,4:既に改善に取り組んでいる（6か月未満）,5:既に改善に取り組んでいる（6か月以上）"},
+	{"2008/04/01", "205", "160", "保健指導の希望", monshinHai},
+
+	// 第3期（平成30年度～）。体重の増減・夜食の質問が咀嚼・間食になり、食べる速度は3択
+	{"2018/04/01", "176", "139", "服薬１_血圧", monshinHai},
+	{"2018/04/01", "179", "140", "服薬２_血糖", monshinHai},
+	{"2018/04/01", "182", "141", "服薬３_脂質", monshinHai},
+	{"2018/04/01", "185", "142", "既往歴１_脳血管", monshinHai},
+	{"2018/04/01", "186", "143", "既往歴２_心血管", monshinHai},
+	{"2018/04/01", "187", "144", "既往歴３_腎不全人工透析", monshinHai},
+	{"2018/04/01", "188", "145", "貧血", monshinHai},
+	{"2018/04/01", "189", "146", "喫煙", monshinHai},
+	{"2018/04/01", "190", "147", "２０歳からの体重変化", monshinHai},
+	{"2018/04/01", "191", "148", "３０分以上の運動習慣", monshinHai},
+	{"2018/04/01", "192", "149", "歩行又は身体活動", monshinHai},
+	{"2018/04/01", "193", "150", "歩行速度", monshinHai},
+	{"2018/04/01", "195", "151", "食事についての咀嚼", "1:何でもかんで食べることができる|何でも,2:歯や歯ぐき、かみあわせなど気になる部分があり、かみにくいことがある|かみにくい,3:ほとんどかめない"},
+	{"2018/04/01", "196", "152", "食べ方１_早食い等", "1:速い,2:ふつう|普通,3:遅い"},
+	{"2018/04/01", "197", "153", "食べ方２_就寝前", monshinHai},
+	{"2018/04/01", "199", "154", "食べ方３_三食以外の間食", "1:毎日,2:時々,3:ほとんど摂取しない|ほとんど"},
+	{"2018/04/01", "200", "155", "食習慣", monshinHai},
+	{"2018/04/01", "201", "156", "飲酒", "1:毎日,2:時々,3:ほとんど飲まない|飲まない|飲めない|ほとんど飲まない（飲めない）"},
+	{"2018/04/01", "202", "157", "飲酒量", "1:1合未満,2:1～2合未満,3:2～3合未満,4:3合以上"},
+	{"2018/04/01", "203", "158", "睡眠", monshinHai},
+	{"2018/04/01", "204", "159", "生活習慣の改善", "1:改善するつもりはない,2:改善するつもりである,3:近いうちに改善するつもりであり、少しずつ始めている,4:既に改善に取り組んでいる（6か月未満）,5:既に改善に取り組んでいる（6か月以上）"},
+	{"2018/04/01", "205", "160", "保健指導の希望", monshinHai},
+
+	// 第4期（令和6年度～）。喫煙は3択、飲酒の頻度・量が細かくなり、保健指導の希望が特定保健指導の受診歴になった
+	{"2024/04/01", "176", "139", "服薬１_血圧", monshinHai},
+	{"2024/04/01", "179", "140", "服薬２_血糖", monshinHai},
+	{"2024/04/01", "182", "141", "服薬３_脂質", monshinHai},
+	{"2024/04/01", "185", "142", "既往歴１_脳血管", monshinHai},
+	{"2024/04/01", "186", "143", "既往歴２_心血管", monshinHai},
+	{"2024/04/01", "187", "144", "既往歴３_腎不全人工透析", monshinHai},
+	{"2024/04/01", "188", "145", "貧血", monshinHai},
+	{"2024/04/01", "189", "146", "喫煙", "1:はい,2:以前は吸っていたが最近1か月間は吸っていない|以前は吸っていた|禁煙,3:いいえ"},
+	{"2024/04/01", "190", "147", "２０歳からの体重変化", monshinHai},
+	{"2024/04/01", "191", "148", "３０分以上の運動習慣", monshinHai},
+	{"2024/04/01", "192", "149", "歩行又は身体活動", monshinHai},
+	{"2024/04/01", "193", "150", "歩行速度", monshinHai},
+	{"2024/04/01", "195", "151", "食事についての咀嚼", "1:何でもかんで食べることができる|何でも,2:歯や歯ぐき、かみあわせなど気になる部分があり、かみにくいことがある|かみにくい,3:ほとんどかめない"},
+	{"2024/04/01", "196", "152", "食べ方１_早食い等", "1:速い,2:ふつう|普通,3:遅い"},
+	{"2024/04/01", "197", "153", "食べ方２_就寝前", monshinHai},
+	{"2024/04/01", "199", "154", "食べ方３_三食以外の間食", "1:毎日,2:時々,3:ほとんど摂取しない|ほとんど"},
+	{"2024/04/01", "200", "155", "食習慣", monshinHai},
+	{"2024/04/01", "201", "156", "飲酒", "1:毎日,2:週5～6日,3:週3～4日,4:週1～2日,5:月に1～3日,6:月に1日未満,7:やめた,8:飲まない（飲めない）|飲まない|飲めない"},
+	{"2024/04/01", "202", "157", "飲酒量", "1:1合未満,2:1～2合未満,3:2～3合未満,4:3～5合未満,5:5合以上"},
+	{"2024/04/01", "203", "158", "睡眠", monshinHai},
+	{"2024/04/01", "204", "159", "生活習慣の改善", "1:改善するつもりはない,2:改善するつもりである,3:近いうちに改善するつもりであり、少しずつ始めている,4:既に改善に取り組んでいる（6か月未満）,5:既に改善に取り組んでいる（6か月以上）"},
+	{"2024/04/01", "", "160", "特定保健指導の受診歴", monshinHai},
+}
+
+// 受診日（yyyy/mm/dd）の質問票の版（適用開始日）。どの版より前なら最初の版
+func monshinBan(jusinbi string) string {
+	ban, saisyo := "", ""
+	for _, m := range monshin {
+		if saisyo == "" || m[0] < saisyo {
+			saisyo = m[0]
+		}
+		if m[0] <= jusinbi && m[0] > ban {
+			ban = m[0]
+		}
+	}
+	if ban == "" {
+		return saisyo
+	}
+	return ban
+}
+
+// 問診の回答の誤り（受診者ID → 「列.項目名:回答」）。書式の確認で使う
+var monshinAyamari = map[string][]string{}
+
+// 回答をコードにする（空欄は空欄。選択肢になければfalse）
+// 表記で合わなければ番号で合わせる（bangouは番号だけで合わせたとき）
+func monshinCode(atai string, kaitou string) (code string, ok bool, bangou bool) {
+	s := strings.TrimSpace(string(norm.NFKC.Bytes([]byte(atai))))
+	if s == "" {
+		return "", true, false
+	}
+	for _, k := range strings.Split(kaitou, ",") {
+		kv := strings.SplitN(k, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		for _, h := range strings.Split(kv[1], "|") {
+			if s == strings.TrimSpace(string(norm.NFKC.Bytes([]byte(h)))) {
+				return strings.TrimSpace(kv[0]), true, false
+			}
+		}
+	}
+	for _, k := range strings.Split(kaitou, ",") {
+		kv := strings.SplitN(k, ":", 2)
+		if len(kv) == 2 && s == strings.TrimSpace(kv[0]) {
+			return s, true, true
+		}
+	}
+	return strings.TrimSpace(atai), false, false
+}
+
+// 出力列の回答の番号が、版によって違う意味（最初の表記）で使われているか
+func monshinImiHenka(col string, code string) bool {
+	imi := ""
+	for _, m := range monshin {
+		if m[1] == "" || m[1] != col {
+			continue
+		}
+		for _, k := range strings.Split(m[4], ",") {
+			kv := strings.SplitN(k, ":", 2)
+			if len(kv) != 2 || strings.TrimSpace(kv[0]) != code {
+				continue
+			}
+			h := strings.TrimSpace(string(norm.NFKC.Bytes([]byte(strings.Split(kv[1], "|")[0]))))
+			if imi != "" && imi != h {
+				return true
+			}
+			imi = h
+		}
+	}
+	return false
+}
+
+// 受診日の版の出力列の回答（表示用。report.goのkaitouで使う）
+func monshinHyou(jusinbi string, col int) string {
+	ban := monshinBan(strings.Replace(jusinbi, "-", "/", -1))
+	for _, m := range monshin {
+		if m[0] == ban && m[1] == strconv.Itoa(col) {
+			return m[4]
+		}
+	}
+	return ""
+}
+
+// 176～205.問診
+func monshinSet(cRec []string, inRec []string) {
+	ban := monshinBan(cRec[19])
+	for _, m := range monshin {
+		if m[0] != ban {
+			continue
+		}
+
+		s := ""
+		if i, err := strconv.Atoi(m[2]); err == nil {
+			if i < len(inRec) {
+				s = inRec[i]
+			}
+		} else {
+			s = inVal(inRec, m[2])
+		}
+		code, ok, bangou := monshinCode(s, m[4])
+		if !ok {
+			log.Printf("問診の回答が質問票（%v～）の選択肢にありません 受診者ID:%v %v:%v\r\n", ban, inRec[1], m[3], s)
+		} else if bangou && monshinImiHenka(m[1], code) {
+			log.Printf("問診の回答が番号だけで、質問票の版によって意味が変わります 受診者ID:%v %v:%v\r\n", inRec[1], m[3], s)
+			monshinAyamari[inRec[1]] = append(monshinAyamari[inRec[1]], m[1]+"."+m[3]+":"+s+"（番号だけの回答）")
+		}
+
+		if m[1] == "" {
+			// 2021年形式に列がない質問は備考へ
+			if code != "" {
+				cRec[175] = strings.Trim(cRec[175]+" "+m[3]+":"+kaitou(code, m[4]), " ")
+			}
+			continue
+		}
+		c, err := strconv.Atoi(m[1])
+		if err != nil || c >= len(cRec) {
+			log.Printf("問診の設定が正しくありません:%v\r\n", m)
+			continue
+		}
+		cRec[c] = code
+	}
+}
+
+// 版毎の出力列の回答コード（monshinから作る）
+func monshinCodes() map[string]map[int]map[string]bool {
+	codes := map[string]map[int]map[string]bool{}
+	for _, m := range monshin {
+		c, err := strconv.Atoi(m[1])
+		if err != nil {
+			continue
+		}
+		if codes[m[0]] == nil {
+			codes[m[0]] = map[int]map[string]bool{}
+		}
+		codes[m[0]][c] = map[string]bool{}
+		for _, k := range strings.Split(m[4], ",") {
+			kv := strings.SplitN(k, ":", 2)
+			codes[m[0]][c][strings.TrimSpace(kv[0])] = true
+		}
+	}
+	return codes
+}
+
+// 問診の回答が受診日の版の回答コードか確かめる（書式の確認で使う。denaiは出力する形式にない列）
+func monshinKakunin(cRec []string, head []string, codes map[string]map[int]map[string]bool, denai map[int]bool) []string {
+	ayamari := append([]string{}, monshinAyamari[cRec[2]]...)
+	ban := monshinBan(cRec[19])
+	for c, code := range codes[ban] {
+		if c >= len(cRec) || denai[c] || cRec[c] == "" {
+			continue
+		}
+		if !code[cRec[c]] {
+			ayamari = append(ayamari, strconv.Itoa(c)+"."+head[c]+":"+cRec[c]+"（質問票"+ban+"～）")
+		}
+	}
+	sort.Strings(ayamari)
+	return ayamari
+}
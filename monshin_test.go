@@ -0,0 +1,116 @@
+package main
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestMonshinBan(t *testing.T) {
+	tests := []struct{ jusinbi, want string }{
+		{"2000/01/01", "2008/04/01"},
+		{"2018/03/31", "2008/04/01"},
+		{"2018/04/01", "2018/04/01"},
+		{"2024/03/31", "2018/04/01"},
+		{"2024/05/01", "2024/04/01"},
+	}
+	for _, tt := range tests {
+		if got := monshinBan(tt.jusinbi); got != tt.want {
+			t.Errorf("monshinBan(%q) = %q, want %q", tt.jusinbi, got, tt.want)
+		}
+	}
+}
+
+// 表記で合わせてから番号で合わせる
+func TestMonshinCode(t *testing.T) {
+	tests := []struct {
+		atai, kaitou string
+		code         string
+		ok, bangou   bool
+	}{
+		{"", monshinHai, "", true, false},
+		{"はい", monshinHai, "1", true, false},
+		{" 無し ", monshinHai, "2", true, false},
+		{"１", monshinHai, "1", true, true},
+		{"3", monshinHai, "3", false, false},
+		{"わからない", monshinHai, "わからない", false, false},
+		{"ほとんど飲まない（飲めない）", "1:毎日,2:時々,3:ほとんど飲まない|飲まない|飲めない|ほとんど飲まない（飲めない）", "3", true, false},
+		{"1合未満", "1:1合未満,2:1～2合未満", "1", true, false},
+		// 表記が他の番号と同じなら表記を優先する
+		{"2", "1:2,2:3", "1", true, false},
+		{"3", "1:2,2:3", "2", true, false},
+	}
+	for _, tt := range tests {
+		code, ok, bangou := monshinCode(tt.atai, tt.kaitou)
+		if code != tt.code || ok != tt.ok || bangou != tt.bangou {
+			t.Errorf("monshinCode(%q, %q) = %q, %v, %v, want %q, %v, %v",
+				tt.atai, tt.kaitou, code, ok, bangou, tt.code, tt.ok, tt.bangou)
+		}
+	}
+}
+
+// 版によって番号の意味が変わる回答
+func TestMonshinImiHenka(t *testing.T) {
+	tests := []struct {
+		col, code string
+		want      bool
+	}{
+		{"176", "1", false},
+		{"189", "1", false},
+		{"189", "2", true},
+		{"189", "3", false},
+		{"201", "1", false},
+		{"201", "3", true},
+		{"202", "4", true},
+		{"196", "1", true},
+		{"197", "1", false},
+		{"", "1", false},
+	}
+	for _, tt := range tests {
+		if got := monshinImiHenka(tt.col, tt.code); got != tt.want {
+			t.Errorf("monshinImiHenka(%q, %q) = %v, want %v", tt.col, tt.code, got, tt.want)
+		}
+	}
+}
+
+// 受診日の版の回答コードで確かめ、番号だけの回答の誤りも含める
+func TestMonshinKakunin(t *testing.T) {
+	moto := monshinAyamari
+	defer func() { monshinAyamari = moto }()
+	monshinAyamari = map[string][]string{"ID0002": {"189.喫煙:2（番号だけの回答）"}}
+
+	head := make([]string, 222)
+	for i := range head {
+		head[i] = "h" + strconv.Itoa(i)
+	}
+	rec := func(id, jusinbi string, kv map[int]string) []string {
+		r := make([]string, 222)
+		r[2], r[19] = id, jusinbi
+		for k, v := range kv {
+			r[k] = v
+		}
+		return r
+	}
+	codes := monshinCodes()
+
+	tests := []struct {
+		name  string
+		cRec  []string
+		denai map[int]bool
+		want  []string
+	}{
+		{"第3期の回答", rec("ID0001", "2019/06/01", map[int]string{189: "1", 201: "3"}), nil, []string{}},
+		{"第3期にない回答", rec("ID0001", "2019/06/01", map[int]string{201: "5"}), nil,
+			[]string{"201.h201:5（質問票2018/04/01～）"}},
+		{"第4期の回答", rec("ID0001", "2024/06/01", map[int]string{201: "5", 189: "3"}), nil, []string{}},
+		{"第2期にない列", rec("ID0001", "2017/06/01", map[int]string{195: "1"}), nil, []string{}},
+		{"出力しない列", rec("ID0001", "2019/06/01", map[int]string{201: "5"}), map[int]bool{201: true}, []string{}},
+		{"番号だけの回答", rec("ID0002", "2024/06/01", map[int]string{189: "2"}), nil,
+			[]string{"189.喫煙:2（番号だけの回答）"}},
+	}
+	for _, tt := range tests {
+		if got := monshinKakunin(tt.cRec, head, codes, tt.denai); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: monshinKakunin = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
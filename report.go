@@ -0,0 +1,291 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// 会社毎の健診結果レポート（HTML）
+// 健診データと同じ場所に作成する。グラフはSVGで埋め込み、外部のファイルやサイトは使わない
+
+// 判定の分布を出す項目（健診データ3行目の項目名）
+var reportHantei = []string{
+	"BMI判定", "腹囲判定", "血圧判定", "中性脂肪判定", "HDLコレステロール判定", "LDLコレステロール判定",
+	"GOT(AST)判定", "GPT(ALT)判定", "γ-GT(γ-GTP)判定", "血清クレアチニン判定", "eGFR判定", "血清尿酸判定",
+	"空腹時血糖判定", "随時血糖判定", "HbA1c（NGSP)判定", "尿糖判定", "尿蛋白判定",
+	"血色素量(ヘモグロビン値)判定", "胸部X線判定②,胸部X線判定①", "心電図判定",
+}
+
+// 問診の項目（回答の表示は受診日の質問票の版による。monshin.go）
+var reportMonshin = []string{"喫煙", "３０分以上の運動習慣", "歩行又は身体活動", "飲酒", "飲酒量", "睡眠"}
+
+// 区分毎の有所見率を出す最小の人数（これより少ない区分は個人が分かるので表示しない）
+const reportSaisyou = 10
+
+var reportIro = []string{"#4e79a7", "#59a14f", "#edc948", "#f28e2b", "#e15759", "#b07aa1", "#9c755f", "#76b7b2", "#bab0ac"}
+
+func nendai(s string) string {
+	n, err := strconv.Atoi(strings.TrimSpace(string(norm.NFKC.Bytes([]byte(s)))))
+	switch {
+	case err != nil:
+		return "不明"
+	case n < 30:
+		return "29歳以下"
+	case n >= 60:
+		return "60歳以上"
+	}
+	return fmt.Sprintf("%d～%d歳", n/10*10, n/10*10+9)
+}
+
+func seibetsu(s string) string {
+	switch strings.TrimSpace(string(norm.NFKC.Bytes([]byte(s)))) {
+	case "1", "男", "男性":
+		return "男性"
+	case "2", "女", "女性":
+		return "女性"
+	}
+	return "不明"
+}
+
+// 回答番号を表示用にする（表にない値はそのまま、空欄は未回答）
+func kaitou(s, hyou string) string {
+	s = strings.TrimSpace(string(norm.NFKC.Bytes([]byte(s))))
+	if s == "" {
+		return "未回答"
+	}
+	for _, h := range strings.Split(hyou, ",") {
+		kv := strings.SplitN(h, ":", 2)
+		if len(kv) == 2 && kv[0] == s {
+			// 別の表記（|の後）は表示しない
+			return strings.SplitN(kv[1], "|", 2)[0]
+		}
+	}
+	return s
+}
+
+// 値毎の件数（表示順は値の順）
+func bunpu(vals []string) ([]string, []int) {
+	count := map[string]int{}
+	cats := make([]string, 0)
+	for _, v := range vals {
+		if _, ok := count[v]; !ok {
+			cats = append(cats, v)
+		}
+		count[v]++
+	}
+	sort.Strings(cats)
+	n := make([]int, len(cats))
+	for i, c := range cats {
+		n[i] = count[c]
+	}
+	return cats, n
+}
+
+// 帯グラフ（行毎に区分の割合を積み上げる）
+func svgStack(labels []string, cats []string, counts [][]int) string {
+	const lw, bw, rh = 220, 460, 24
+	h := rh*len(labels) + 30
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-size="12">`, lw+bw+60, h)
+	for r, label := range labels {
+		y := r * rh
+		total := 0
+		for _, n := range counts[r] {
+			total += n
+		}
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`, lw-6, y+16, html.EscapeString(label))
+		x := float64(lw)
+		for c, n := range counts[r] {
+			if n == 0 || total == 0 {
+				continue
+			}
+			w := float64(bw) * float64(n) / float64(total)
+			fmt.Fprintf(&b, `<rect x="%.1f" y="%d" width="%.1f" height="%d" fill="%s"><title>%s %d人 (%.1f%%)</title></rect>`,
+				x, y+3, w, rh-6, reportIro[c%len(reportIro)], html.EscapeString(cats[c]), n, float64(n)*100/float64(total))
+			x += w
+		}
+		fmt.Fprintf(&b, `<text x="%d" y="%d">%d人</text>`, lw+bw+6, y+16, total)
+	}
+	x := lw
+	for c, cat := range cats {
+		y := rh*len(labels) + 8
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="12" height="12" fill="%s"/><text x="%d" y="%d">%s</text>`,
+			x, y, reportIro[c%len(reportIro)], x+16, y+11, html.EscapeString(cat))
+		x += 24 + 12*len([]rune(cat))
+	}
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+// 棒グラフ（行毎の割合）
+func svgRate(labels []string, bunsi, bunbo []int) string {
+	const lw, bw, rh = 220, 400, 24
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-size="12">`, lw+bw+140, rh*len(labels)+6)
+	for r, label := range labels {
+		y := r * rh
+		rate := 0.0
+		if bunbo[r] > 0 {
+			rate = float64(bunsi[r]) / float64(bunbo[r])
+		}
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`, lw-6, y+16, html.EscapeString(label))
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="#eee"/>`, lw, y+3, bw, rh-6)
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%.1f" height="%d" fill="#e15759"/>`, lw, y+3, float64(bw)*rate, rh-6)
+		fmt.Fprintf(&b, `<text x="%d" y="%d">%.1f%% (%d/%d人)</text>`, lw+bw+6, y+16, rate*100, bunsi[r], bunbo[r])
+	}
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+// 人数の少ない区分はまとめ、まとめても少なければ出さない（sukunaiはまとめた区分の数）
+func reportMatome(keys []string, bunsi, bunbo map[string]int) (labels []string, si, bo []int, sukunai int) {
+	labels = make([]string, 0)
+	si = make([]int, 0)
+	bo = make([]int, 0)
+	sukunaiSi, sukunaiBo := 0, 0
+	for _, k := range keys {
+		if bunbo[k] < reportSaisyou {
+			sukunai++
+			sukunaiSi += bunsi[k]
+			sukunaiBo += bunbo[k]
+			continue
+		}
+		labels = append(labels, k)
+		si = append(si, bunsi[k])
+		bo = append(bo, bunbo[k])
+	}
+	if sukunaiBo >= reportSaisyou {
+		labels = append(labels, fmt.Sprintf("その他（%d人未満の%d区分）", reportSaisyou, sukunai))
+		si = append(si, sukunaiSi)
+		bo = append(bo, sukunaiBo)
+	}
+	return labels, si, bo, sukunai
+}
+
+func reportSave(htmlName, kaisyaName string, head []string, cRecs [][]string) {
+	if len(cRecs) == 0 {
+		return
+	}
+	col := koumokuCol(head)
+	val := func(row []string, name string) string {
+		if v := koumokuVal(row, col, name); len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"ja\"><head><meta charset=\"UTF-8\">")
+	fmt.Fprintf(&b, "<title>%s 健診結果レポート</title>", html.EscapeString(kaisyaName))
+	b.WriteString("<style>body{font-family:sans-serif;margin:24px}h2{border-bottom:1px solid #999;margin-top:32px}</style></head><body>\n")
+	fmt.Fprintf(&b, "<h1>%s 健診結果レポート</h1><p>受診者 %d人</p>\n", html.EscapeString(kaisyaName), len(cRecs))
+
+	// 判定の分布
+	hantei := []string{"A", "B", "C", "D", "E", "F", "G", "その他"}
+	labels := make([]string, 0)
+	counts := make([][]int, 0)
+	for _, name := range reportHantei {
+		n := make([]int, len(hantei))
+		ari := false
+		for _, row := range cRecs {
+			v := strings.ToUpper(string(norm.NFKC.Bytes([]byte(val(row, name)))))
+			if v == "" {
+				continue
+			}
+			ari = true
+			k := strings.Index("ABCDEFG", v)
+			if len(v) != 1 || k < 0 {
+				k = len(hantei) - 1
+			}
+			n[k]++
+		}
+		if ari {
+			labels = append(labels, strings.TrimSuffix(strings.Split(name, ",")[0], "判定"))
+			counts = append(counts, n)
+		}
+	}
+	b.WriteString("<h2>項目別の判定</h2>\n")
+	b.WriteString(svgStack(labels, hantei, counts) + "\n")
+
+	// 年代・性別・所属別の有所見率（有所見基準のいずれかに該当）
+	bunrui := []struct {
+		name string
+		key  func(row []string) string
+	}{
+		{"年代別", func(row []string) string { return nendai(val(row, "年齢")) }},
+		{"男女別", func(row []string) string { return seibetsu(val(row, "性別")) }},
+		{"所属別", func(row []string) string {
+			if s := val(row, "所属名称"); s != "" {
+				return s
+			}
+			if s := val(row, "所属コード"); s != "" {
+				return s
+			}
+			return "（所属なし）"
+		}},
+	}
+	for _, br := range bunrui {
+		keys := make([]string, 0)
+		bunsi := map[string]int{}
+		bunbo := map[string]int{}
+		for _, row := range cRecs {
+			k := br.key(row)
+			if _, ok := bunbo[k]; !ok {
+				keys = append(keys, k)
+			}
+			bunbo[k]++
+			_, ari := yusyokenCheck(row, col)
+			for _, a := range ari {
+				if a {
+					bunsi[k]++
+					break
+				}
+			}
+		}
+		sort.Strings(keys)
+		labels, si, bo, sukunai := reportMatome(keys, bunsi, bunbo)
+
+		fmt.Fprintf(&b, "<h2>%sの有所見率</h2>\n", br.name)
+		if sukunai > 0 {
+			fmt.Fprintf(&b, "<p>%d人未満の区分は個人が分からないよう、まとめるか表示していません。</p>\n", reportSaisyou)
+		}
+		if len(labels) > 0 {
+			b.WriteString(svgRate(labels, si, bo) + "\n")
+		}
+	}
+
+	// メタボリックシンドローム判定・支援レベル・問診
+	bubun := [][]string{
+		{"メタボリックシンドローム判定", "1:基準該当,2:予備群該当,3:非該当,4:判定不能"},
+		{"支援レベル", "1:積極的支援,2:動機付け支援,3:なし,4:判定不能"},
+	}
+	chart := func(name string, hyou func(row []string) string) {
+		vals := make([]string, 0)
+		for _, row := range cRecs {
+			vals = append(vals, kaitou(val(row, name), hyou(row)))
+		}
+		cats, n := bunpu(vals)
+		b.WriteString(svgStack([]string{name}, cats, [][]int{n}) + "\n")
+	}
+	for _, m := range bubun {
+		fmt.Fprintf(&b, "<h2>%s</h2>\n", m[0])
+		chart(m[0], func(row []string) string { return m[1] })
+	}
+	b.WriteString("<h2>問診（生活習慣）</h2>\n")
+	for _, name := range reportMonshin {
+		c := col[name]
+		chart(name, func(row []string) string { return monshinHyou(val(row, "受診日"), c) })
+	}
+
+	b.WriteString("</body></html>\n")
+	err := ioutil.WriteFile(htmlName, []byte(b.String()), 0666)
+	failOnError(err)
+	log.Print("健診結果レポートを作成しました:" + htmlName + "\r\n")
+}
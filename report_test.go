@@ -0,0 +1,57 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// reportSaisyou（10人）未満の区分はまとめ、まとめても10人未満なら出さない
+func TestReportMatome(t *testing.T) {
+	tests := []struct {
+		name         string
+		keys         []string
+		bunsi, bunbo map[string]int
+		labels       []string
+		si, bo       []int
+		sukunai      int
+	}{
+		{"すべて10人以上",
+			[]string{"営業", "総務"}, map[string]int{"営業": 3, "総務": 5}, map[string]int{"営業": 10, "総務": 20},
+			[]string{"営業", "総務"}, []int{3, 5}, []int{10, 20}, 0},
+		{"少ない区分をまとめる",
+			[]string{"営業", "総務", "経理"}, map[string]int{"営業": 3, "総務": 2, "経理": 1}, map[string]int{"営業": 10, "総務": 6, "経理": 4},
+			[]string{"営業", "その他（10人未満の2区分）"}, []int{3, 3}, []int{10, 10}, 2},
+		{"まとめても少なければ出さない",
+			[]string{"営業", "総務", "経理"}, map[string]int{"営業": 3, "総務": 2, "経理": 1}, map[string]int{"営業": 10, "総務": 5, "経理": 4},
+			[]string{"営業"}, []int{3}, []int{10}, 2},
+		{"すべて少ない",
+			[]string{"男性", "女性"}, map[string]int{"男性": 4, "女性": 1}, map[string]int{"男性": 9, "女性": 1},
+			[]string{"その他（10人未満の2区分）"}, []int{5}, []int{10}, 2},
+		{"1区分だけで少ない",
+			[]string{"男性"}, map[string]int{"男性": 1}, map[string]int{"男性": 9},
+			[]string{}, []int{}, []int{}, 1},
+	}
+	for _, tt := range tests {
+		labels, si, bo, sukunai := reportMatome(tt.keys, tt.bunsi, tt.bunbo)
+		if !reflect.DeepEqual(labels, tt.labels) || !reflect.DeepEqual(si, tt.si) || !reflect.DeepEqual(bo, tt.bo) || sukunai != tt.sukunai {
+			t.Errorf("%v: reportMatome = %q, %v, %v, %v, want %q, %v, %v, %v",
+				tt.name, labels, si, bo, sukunai, tt.labels, tt.si, tt.bo, tt.sukunai)
+		}
+	}
+}
+
+func TestNendai(t *testing.T) {
+	tests := []struct{ s, want string }{
+		{"29", "29歳以下"},
+		{"30", "30～39歳"},
+		{"４５", "40～49歳"},
+		{"59", "50～59歳"},
+		{"60", "60歳以上"},
+		{"", "不明"},
+	}
+	for _, tt := range tests {
+		if got := nendai(tt.s); got != tt.want {
+			t.Errorf("nendai(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 変換した健診データの履歴は実行ファイルと同じ場所の「履歴」フォルダに残す
+// 　実行一覧.txt　　実行ID, 実行日時, 入力ファイル, 出力先, 件数
+// 　健診データ.txt　実行ID, 所属cd, 会社名, 区分, キー, 受診日, 健診データの各列, 状態, 既往歴ICD-10, 制限列
+// 形式はタブ区切り（UTF-8）で、実行毎に項目名の行（先頭が「実行ID」）と一緒に追記する。
+// 健診データは項目の制限・提供除外の前のすべての列で、区分は本人か家族。
+// 提供除外・要確認で送付しなかった方も、状態にその理由を付けて残す。
+// 制限列は事業者へ提供しなかった列（項目の制限）で、経年のシートで過去の結果にも当てはめる。
+// キーは社員番号（家族は「社員番号-扶養番号」、社員番号や扶養番号がなければ「ID:受診者ID」）
+// 同じ会社・区分・キー・受診日は後の実行のものを正とする
+
+// 履歴の健診データの先頭の項目（この後に健診データの列が続く）と、最後の項目
+var rirekiKoumoku = []string{"実行ID", "所属cd", "会社名", "区分", "キー", "受診日"}
+var rirekiKoumokuAto = []string{"状態", "既往歴ICD-10", "制限列"}
+
+// 今回の実行で健診データ.txtに項目名の行を書いたか
+var rirekiMidashi = false
+
+// 履歴の照会（-rireki 社員番号 または 受診者ID で、その方の履歴をすべて一覧にする）
+var rirekiSyoukai = flag.String("rireki", "", "履歴を照会する社員番号または受診者ID")
+
+var rirekiRunID = time.Now().Format("20060102150405.000")
+var rirekiKensu = 0
+
+func rirekiDir() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return "./履歴/"
+	}
+	return filepath.Dir(exe) + "/履歴/"
+}
+
+func rirekiKey(cRec []string) string {
+	switch {
+	case cRec[0] == "":
+	case cRec[12] != "家族":
+		return cRec[0]
+	case cRec[11] != "":
+		return cRec[0] + "-" + cRec[11]
+	}
+	return "ID:" + cRec[2]
+}
+
+// 履歴のファイルに追記する（ファイルがなければ項目名を付けて作る。headがnilなら付けない）
+func rirekiAppend(name string, head []string, rows [][]string) {
+	err := os.MkdirAll(rirekiDir(), 0777)
+	failOnError(err)
+
+	filename := rirekiDir() + name
+	_, err = os.Stat(filename)
+	atarashii := os.IsNotExist(err)
+
+	outfile, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	failOnError(err)
+	defer outfile.Close()
+
+	writer := csv.NewWriter(outfile)
+	writer.Comma = '\t'
+	writer.UseCRLF = true
+	if atarashii && head != nil {
+		writer.Write(head)
+	}
+	for _, row := range rows {
+		writer.Write(row)
+	}
+	writer.Flush()
+	failOnError(writer.Error())
+}
+
+// 履歴の健診データの項目名
+func rirekiHead(head []string) []string {
+	return append(append(append([]string{}, rirekiKoumoku...), head...), rirekiKoumokuAto...)
+}
+
+// 会社毎の変換結果を履歴に残す（cRecsは健診データの列の後に状態・既往歴ICD-10・制限列を付けたもの）
+func rirekiSave(coRec []string, head []string, cRecs [][]string) {
+	if len(cRecs) == 0 {
+		return
+	}
+	rows := make([][]string, 0)
+	if !rirekiMidashi {
+		rows = append(rows, rirekiHead(head))
+		rirekiMidashi = true
+	}
+	for _, cRec := range cRecs {
+		row := []string{rirekiRunID, coRec[0], coRec[1], cRec[12], rirekiKey(cRec), cRec[19]}
+		rows = append(rows, append(row, cRec...))
+	}
+	rirekiAppend("健診データ.txt", nil, rows)
+	rirekiKensu += len(cRecs)
+}
+
+// 実行の記録
+func rirekiRun(infile, outDir string) {
+	rirekiAppend("実行一覧.txt", []string{"実行ID", "実行日時", "入力ファイル", "出力先", "件数"},
+		[][]string{{rirekiRunID, time.Now().Format("2006/01/02 15:04:05"), infile, outDir, fmt.Sprint(rirekiKensu)}})
+	log.Printf("履歴に残しました 実行ID:%v %v件\r\n", rirekiRunID, rirekiKensu)
+}
+
+// 履歴のファイルをすべて読み、各行を直前の項目名の行で今回の項目名の並びにそろえる
+// （形式の変わる前の行も同じ列で扱える。1行目は今回の項目名）
+func rirekiRead(name string, head []string) [][]string {
+	infile, err := os.Open(rirekiDir() + name)
+	if err != nil {
+		return nil
+	}
+	defer infile.Close()
+
+	reader := csv.NewReader(infile)
+	reader.Comma = '\t'
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	ichi := koumokuIchi(head)
+	rowHead := head
+	rows := [][]string{head}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else {
+			failOnError(err)
+		}
+		if len(row) > 0 && row[0] == "実行ID" {
+			rowHead = row
+			continue
+		}
+		rows = append(rows, koumokuSoroe(row, rowHead, ichi, len(head)))
+	}
+	return rows
+}
+
+// 履歴の健診データをすべて読む（1行目は項目名）
+func rirekiLoad() [][]string {
+	return rirekiRead("健診データ.txt", rirekiHead(headRows()[2]))
+}
+
+// 社員番号か受診者IDの合う履歴を、入力ファイルと同じ場所の「履歴照会（社内用）」に書き出す
+func rirekiCreate(key, dir string) {
+	rows := rirekiLoad()
+	if len(rows) < 2 {
+		log.Print("履歴がありません\r\n")
+		return
+	}
+
+	k := len(rirekiKoumoku)
+	hits := make([][]string, 0)
+	for _, row := range rows[1:] {
+		if len(row) <= k+2 {
+			continue
+		}
+		if row[k] == key || row[k+2] == key || strings.HasPrefix(row[4], key+"-") {
+			hits = append(hits, row)
+		}
+	}
+	if len(hits) == 0 {
+		log.Print("履歴に該当する方がいません:" + key + "\r\n")
+		return
+	}
+
+	day := time.Now()
+	naibuSave(dir+"履歴照会（社内用）"+key+"_"+day.Format("20060102")+".xlsx", rows[0], hits)
+	log.Printf("履歴を照会しました:%v %v件\r\n", key, len(hits))
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/tealeg/xlsx"
+)
+
+// 差分の納品（-sabun で、前回までに送付したものを除き、新規と訂正の方だけを出力する）
+// 送付したものは出力の検証が済んでから履歴フォルダの送付済.txtに、会社・区分・キー・受診日毎の
+// 内容のハッシュと送付した内容で残す（-sabunを付けない出力は記録しない）
+// 　送付済.txt　実行ID, 所属cd, 区分, キー, 受診日, ハッシュ, 健診データの各列
+// 訂正は前回送付した内容と比べて、変わった列を「訂正箇所」シートに載せる
+// 訂正の方は訂正のファイルに分け、訂正箇所の区分に印を付ける（健診データの列は変えないので文字数を超えない）
+// （履歴の健診データは項目の制限の前のものなので、送付した内容は送付済.txtに持つ）
+var sabunMode = flag.Bool("sabun", false, "前回までに送付した方を除き、新規と訂正の方だけを出力する")
+
+// 訂正箇所の区分に付ける印
+const sabunTeisei = "訂正"
+
+// 送付済（キー → 実行ID, ハッシュ）と、前回送付した内容
+var souhuzumi map[string][]string
+var souhuRireki map[string][]string
+
+// 送付済.txtの先頭の項目（この後に健診データの列が続く）
+var souhuKoumoku = []string{"実行ID", "所属cd", "区分", "キー", "受診日", "ハッシュ"}
+
+func souhuKey(coRec []string, kubun string, cRec []string) string {
+	return coRec[0] + "\t" + kubun + "\t" + rirekiKey(cRec) + "\t" + cRec[19]
+}
+
+func souhuHash(cRec []string) string {
+	h := sha256.Sum256([]byte(strings.Join(cRec, "\t")))
+	return hex.EncodeToString(h[:])
+}
+
+func souhuLoad() {
+	souhuzumi = map[string][]string{}
+	souhuRireki = map[string][]string{}
+
+	rows := rirekiRead("送付済.txt", append(append([]string{}, souhuKoumoku...), headRows()[2]...))
+	if len(rows) < 2 {
+		return
+	}
+	n := len(souhuKoumoku)
+	for _, row := range rows[1:] {
+		// 後に送付したものが正
+		k := strings.Join(row[1:5], "\t")
+		souhuzumi[k] = []string{row[0], row[5]}
+
+		// 送付した内容のない行（ハッシュだけを残していた頃の行）
+		if row[n+2] == "" {
+			delete(souhuRireki, k)
+			continue
+		}
+		souhuRireki[k] = row[n:]
+	}
+}
+
+// 送付したもの（出力の検証が済むまでは記録しない）
+var souhuMachi = make([][]string, 0)
+
+// 送付したものを記録に加える
+func souhuKiroku(coRec []string, kubun string, cRecs [][]string) {
+	for _, cRec := range cRecs {
+		row := []string{rirekiRunID, coRec[0], kubun, rirekiKey(cRec), cRec[19], souhuHash(cRec)}
+		souhuMachi = append(souhuMachi, append(row, cRec...))
+	}
+}
+
+// 出力の検証が済んだら、送付したものを送付済.txtに記録する
+func souhuRun() {
+	if len(souhuMachi) == 0 {
+		return
+	}
+	rows := [][]string{append(append([]string{}, souhuKoumoku...), headRows()[2]...)}
+	rirekiAppend("送付済.txt", nil, append(rows, souhuMachi...))
+	log.Printf("送付済に記録しました %v件\r\n", len(souhuMachi))
+}
+
+// 新規・訂正に分け、訂正は変わった列を調べる
+func sabunBunrui(coRec []string, kubun string, head []string, cRecs [][]string) ([][]string, [][]string, [][]string) {
+	if souhuzumi == nil {
+		souhuLoad()
+	}
+
+	sinki := make([][]string, 0)
+	teisei := make([][]string, 0)
+	kasyo := make([][]string, 0)
+	kaburi := map[string]bool{}
+	for _, cRec := range cRecs {
+		k := souhuKey(coRec, kubun, cRec)
+		if kaburi[k] {
+			log.Printf("同じ社員番号・受診日の方が複数いるため差分を正しく取れません 受診者ID:%v\r\n", cRec[2])
+		}
+		kaburi[k] = true
+		s, ok := souhuzumi[k]
+		switch {
+		case !ok:
+			sinki = append(sinki, cRec)
+		case s[1] != souhuHash(cRec):
+			teisei = append(teisei, cRec)
+			mae, ari := souhuRireki[k]
+			if !ari {
+				kasyo = append(kasyo, []string{sabunTeisei, cRec[0], cRec[2], cRec[14], cRec[19], "", "前回送付した内容が送付済.txtにありません", "", "", s[0]})
+				continue
+			}
+			for i := range cRec {
+				m := ""
+				if i < len(mae) {
+					m = mae[i]
+				}
+				if m != cRec[i] {
+					kasyo = append(kasyo, []string{sabunTeisei, cRec[0], cRec[2], cRec[14], cRec[19], fmt.Sprint(i), head[i], m, cRec[i], s[0]})
+				}
+			}
+		}
+	}
+	return sinki, teisei, kasyo
+}
+
+// 訂正の健診データに訂正箇所のシートを付ける（cRecsの受診者の分だけ）
+func kasyoSheet(excelFile *xlsx.File, kasyo [][]string, cRecs [][]string) {
+	ids := map[string]bool{}
+	for _, cRec := range cRecs {
+		ids[cRec[2]] = true
+	}
+
+	sheet, err := excelFile.AddSheet("訂正箇所")
+	failOnError(err)
+
+	rows := [][]string{{"区分", "社員番号", "受診者ID", "氏名", "受診日", "列", "項目名", "前回の値", "今回の値", "前回の実行ID"}}
+	for _, rec := range kasyo {
+		if ids[rec[2]] {
+			rows = append(rows, rec)
+		}
+	}
+	for _, rec := range rows {
+		row := sheet.AddRow()
+		for _, cell := range rec {
+			row.AddCell().Value = cell
+		}
+	}
+}
+
+// 差分の健診データを出力する（name は「健診データ」か「健診データ（健保組合提出用）」）
+func sabunSave(filename, name, hiduke, kubun string, coRec []string, head [][]string, cRecs [][]string) {
+	sinki, teisei, kasyo := sabunBunrui(coRec, kubun, head[2], cRecs)
+	log.Printf("前回までの送付との差分:%v %v 新規%v件 訂正%v件 送付済%v件\r\n", coRec[1], kubun, len(sinki), len(teisei), len(cRecs)-len(sinki)-len(teisei))
+
+	base := strings.TrimSuffix(name, "）")
+	sep := "（"
+	if base != name {
+		sep = "・"
+	}
+	if len(sinki) > 0 {
+		excelName := filename + coRec[1] + base + sep + "新規）" + hiduke + ".xlsx"
+		dataSave(excelName, coRec, kubun, head, sinki, nil)
+	}
+	if len(teisei) > 0 {
+		excelName := filename + coRec[1] + base + sep + sabunTeisei + "）" + hiduke + ".xlsx"
+		dataSave(excelName, coRec, kubun, head, teisei, kasyo)
+	}
+	souhuKiroku(coRec, kubun, append(sinki, teisei...))
+}
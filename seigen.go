@@ -0,0 +1,149 @@
+package main
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// 事業者へ提供する項目の制限（会社cd, コース, 出力しない列, 既往歴から除くICD-10）
+// 会社cd・コースの「*」はすべてに該当する。本人の同意がない受診者にだけ適用する
+// 既定では人間ドックの法定外項目（定期健診にない検査・追加項目・眼底）と
+// 精神疾患・HIV感染症の既往歴を出力しない
+var koumokuSeigen = [][]string{
+	{"*", "95001001000401", "43,51-53,60-63,67-69,77,87-94,98-107,112,120-122,129-132,135,136,139-148", "F,B20,B21,B22,B23,B24"},
+	{"*", "95001001000402", "43,51-53,60-63,67-69,77,87-94,98-107,112,120-122,129-132,135,136,139-148", "F,B20,B21,B22,B23,B24"},
+}
+
+// 法定外項目の提供に同意した受診者（受診者IDまたは社員番号, 同意日, 備考）
+var douisya = [][]string{}
+
+// 「1,3,98-107」のような列の指定を列番号にする
+func colList(s string) []int {
+	cols := make([]int, 0)
+	for _, c := range strings.Split(s, ",") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+
+		if pos := strings.Index(c, "-"); pos > 0 {
+			from, err1 := strconv.Atoi(c[:pos])
+			to, err2 := strconv.Atoi(c[pos+1:])
+			if err1 != nil || err2 != nil {
+				log.Printf("列の指定が正しくありません:%v\r\n", c)
+				continue
+			}
+			for i := from; i <= to; i++ {
+				cols = append(cols, i)
+			}
+		} else {
+			i, err := strconv.Atoi(c)
+			if err != nil {
+				log.Printf("列の指定が正しくありません:%v\r\n", c)
+				continue
+			}
+			cols = append(cols, i)
+		}
+	}
+	return cols
+}
+
+// 本人の同意（A85パターンの「同意」項目か同意者一覧）
+func douiCheck(inRec []string) bool {
+	if yesNo(inVal(inRec, "同意", "結果提供同意")) == "はい" {
+		return true
+	}
+
+	for _, d := range douisya {
+		key := strings.TrimSpace(string(norm.NFKC.Bytes([]byte(d[0]))))
+		if key != "" && (key == inRec[1] || key == inRec[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// 受診者に適用する項目の制限。同意がある場合や該当する制限がない場合はnil
+// 会社・コースとも一致する行を優先する
+func seigenFind(coCd string, inRec []string) []string {
+	var hit []string
+	hitScore := -1
+	for _, s := range koumokuSeigen {
+		if (s[0] != "*" && s[0] != coCd) || (s[1] != "*" && s[1] != inRec[13]) {
+			continue
+		}
+
+		score := 0
+		if s[0] != "*" {
+			score += 2
+		}
+		if s[1] != "*" {
+			score++
+		}
+		if score > hitScore {
+			hit = s
+			hitScore = score
+		}
+	}
+
+	if hit == nil || douiCheck(inRec) {
+		return nil
+	}
+	return hit
+}
+
+// 既往歴から除くICD-10
+func seigenKiou(seigen []string) []string {
+	if seigen == nil {
+		return nil
+	}
+
+	codes := make([]string, 0)
+	for _, c := range strings.Split(seigen[3], ",") {
+		if c = strings.ToUpper(strings.TrimSpace(c)); c != "" {
+			codes = append(codes, c)
+		}
+	}
+	return codes
+}
+
+// 履歴に残す出力しない列（既往歴からICD-10を除く制限があれば既往歴の列も含める）
+func seigenRetsu(seigen []string) string {
+	if seigen == nil {
+		return ""
+	}
+	if len(seigenKiou(seigen)) > 0 {
+		return strings.Trim(seigen[2]+",33", ",")
+	}
+	return seigen[2]
+}
+
+// 出力しない列を空欄にする
+func seigenSet(cRec []string, seigen []string) {
+	if seigen == nil {
+		return
+	}
+
+	for _, c := range colList(seigen[2]) {
+		if c >= 0 && c < len(cRec) {
+			cRec[c] = ""
+		}
+	}
+}
+
+// 事業者へ提出する1人分の健診データ
+// 同意のない受診者は既往歴を除くICD-10で作り直し、提供しない項目を空欄にしてから文字数を確かめる
+func jigyosyaRec(cRec []string, inRec []string, coRec []string, head []string) []string {
+	jRec := append([]string{}, cRec...)
+
+	if seigen := seigenFind(coRec[0], inRec); seigen != nil {
+		jRec[33], _ = kiouCode(inRec, seigenKiou(seigen))
+		seigenSet(jRec, seigen)
+	}
+
+	mojisuCheck(jRec, head, inRec[1])
+	return jRec
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestColList(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []int
+	}{
+		{"", []int{}},
+		{"1,3", []int{1, 3}},
+		{"51-53, 60", []int{51, 52, 53, 60}},
+		{"x,5", []int{5}},
+	}
+	for _, tt := range tests {
+		if got := colList(tt.s); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("colList(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+// 同意のない受診者にだけ、会社・コースの一致する制限を適用する
+func TestSeigenFind(t *testing.T) {
+	moto, motoDoui := koumokuSeigen, douisya
+	defer func() { koumokuSeigen, douisya = moto, motoDoui }()
+
+	koumokuSeigen = [][]string{
+		{"*", "*", "1", ""},
+		{"*", "DOCK", "2", "F"},
+		{"C001", "DOCK", "3", "F"},
+	}
+	douisya = [][]string{{"ID9999", "2024/04/01", ""}}
+	inHead = map[string]int{}
+
+	rec := func(id, cose string) []string {
+		r := make([]string, 14)
+		r[0], r[1], r[13] = "0000012345", id, cose
+		return r
+	}
+	tests := []struct {
+		name  string
+		coCd  string
+		inRec []string
+		want  string // 出力しない列（制限なしは空欄）
+	}{
+		{"すべてに該当", "C002", rec("ID0001", "TEIKI"), "1"},
+		{"コースが一致", "C002", rec("ID0001", "DOCK"), "2"},
+		{"会社とコースが一致", "C001", rec("ID0001", "DOCK"), "3"},
+		{"同意あり", "C001", rec("ID9999", "DOCK"), ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if s := seigenFind(tt.coCd, tt.inRec); s != nil {
+			got = s[2]
+		}
+		if got != tt.want {
+			t.Errorf("%v: seigenFind = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSeigenSet(t *testing.T) {
+	cRec := []string{"a", "b", "c", "d", "e"}
+	seigenSet(cRec, []string{"*", "*", "1,3-4,9", ""})
+	if want := []string{"a", "", "c", "", ""}; !reflect.DeepEqual(cRec, want) {
+		t.Errorf("seigenSet = %v, want %v", cRec, want)
+	}
+	seigenSet(cRec, nil)
+	if cRec[0] != "a" {
+		t.Errorf("制限がなければ変えない:%v", cRec)
+	}
+}
+
+// 既往歴からICD-10を除く制限があれば、履歴の制限列に既往歴（33）を含める
+func TestSeigenRetsu(t *testing.T) {
+	tests := []struct {
+		seigen []string
+		want   string
+	}{
+		{nil, ""},
+		{[]string{"*", "*", "51-53", ""}, "51-53"},
+		{[]string{"*", "*", "51-53", "F,B20"}, "51-53,33"},
+		{[]string{"*", "*", "", "F"}, "33"},
+	}
+	for _, tt := range tests {
+		if got := seigenRetsu(tt.seigen); got != tt.want {
+			t.Errorf("seigenRetsu(%v) = %q, want %q", tt.seigen, got, tt.want)
+		}
+	}
+}
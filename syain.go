@@ -0,0 +1,148 @@
+package main
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// 社員番号ルール（所属cd, 桁数, 文字種（数字・英数字）, ゼロ埋め（する・しない）, チェックデジット（なし・mod10・mod11））
+// 所属cdの「*」は会社毎のルールがない場合に使う
+var syainRule = [][]string{
+	{"*", "10", "数字", "しない", "なし"},
+}
+
+// 要確認の受診者（受診者ID → 理由）。本人の健診データには出力しない
+var youkakunin = map[string]string{}
+
+func syainRuleFind(coCd string) []string {
+	var rule []string
+	for _, r := range syainRule {
+		if r[0] == coCd {
+			return r
+		}
+		if r[0] == "*" {
+			rule = r
+		}
+	}
+	return rule
+}
+
+// 社員番号を半角にしてハイフン・スペースを取り除き、ルールに従ってゼロ埋めする
+func syainNorm(s string, rule []string) string {
+	s = string(norm.NFKC.Bytes([]byte(s)))
+	for _, c := range []string{"-", " "} {
+		s = strings.Replace(s, c, "", -1)
+	}
+
+	if rule != nil && rule[3] == "する" && suujiCheck(s) {
+		keta, _ := strconv.Atoi(rule[1])
+		for len(s) < keta {
+			s = "0" + s
+		}
+	}
+	return s
+}
+
+// mod10はLuhn方式（右から2桁目から1桁おきに2倍）、mod11は右から重み2～7
+func checkDigit(s string, method string) bool {
+	if !suujiCheck(s) || len(s) < 2 {
+		return false
+	}
+
+	body := s[:len(s)-1]
+	cd := int(s[len(s)-1] - '0')
+	sum := 0
+	switch method {
+	case "mod10":
+		for i := 0; i < len(body); i++ {
+			n := int(body[len(body)-1-i] - '0')
+			if i%2 == 0 {
+				n = n * 2
+			}
+			sum += n/10 + n%10
+		}
+		return (10-sum%10)%10 == cd
+	case "mod11":
+		for i := 0; i < len(body); i++ {
+			sum += int(body[len(body)-1-i]-'0') * (i%6 + 2)
+		}
+		c := 11 - sum%11
+		if c >= 10 {
+			c = 0
+		}
+		return c == cd
+	}
+	return true
+}
+
+// ルールに合わない理由（合っていれば空欄）
+func syainRuleCheck(s string, rule []string) string {
+	if s == "" {
+		return "社員番号なし"
+	}
+	if rule == nil {
+		return ""
+	}
+
+	if keta, err := strconv.Atoi(rule[1]); err == nil && len(s) != keta {
+		return "社員番号が" + rule[1] + "桁ではありません"
+	}
+
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			continue
+		}
+		if rule[2] == "英数字" && ((c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')) {
+			continue
+		}
+		return "社員番号に使えない文字があります"
+	}
+
+	if rule[4] != "" && rule[4] != "なし" && !checkDigit(s, rule[4]) {
+		return "社員番号のチェックデジットが違います"
+	}
+	return ""
+}
+
+// 本人の社員番号をそろえ、ルールに合わない受診者を要確認にする
+func syainCheck(records [][]string) {
+	for _, inRec := range records[1:] {
+		if !honninCheck(inRec) {
+			continue
+		}
+
+		rule := syainRuleFind(inRec[4])
+		inRec[0] = syainNorm(inRec[0], rule)
+		if riyu := syainRuleCheck(inRec[0], rule); riyu != "" {
+			youkakunin[inRec[1]] = riyu
+			log.Printf("%v 受診者ID:%v 社員番号:%v\r\n", riyu, inRec[1], inRec[0])
+		}
+	}
+}
+
+// 要確認者一覧（社内用）。社員番号のない方・ルールに合わない方を載せる
+func youkakuninCreate(filename string, inRecs [][]string, coRecs [][]string) {
+	rows := make([][]string, 0)
+	for _, coRec := range coRecs {
+		for _, inRec := range inRecs[1:] {
+			if !outCheck(inRec, coRec[0]) || !honninCheck(inRec) {
+				continue
+			}
+			if riyu := youkakunin[inRec[1]]; riyu != "" {
+				rows = append(rows, []string{coRec[1], inRec[1], inRec[0], inRec[9], inRec[10], inRec[11], inRec[7], inRec[15], riyu})
+			}
+		}
+	}
+
+	if len(rows) == 0 {
+		return
+	}
+
+	day := time.Now()
+	naibuSave(filename+"要確認者一覧（社内用）"+day.Format("20060102")+".xlsx",
+		[]string{"会社", "受診者ID", "社員番号", "氏名", "カナ", "生年月日", "所属", "受診日", "理由"}, rows)
+}
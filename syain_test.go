@@ -0,0 +1,98 @@
+package main
+
+import "testing"
+
+// mod10はLuhn方式、mod11は右から重み2～7（余りから求めた値が10以上なら0）
+func TestCheckDigit(t *testing.T) {
+	tests := []struct {
+		s, method string
+		want      bool
+	}{
+		{"79927398713", "mod10", true},
+		{"79927398710", "mod10", false},
+		{"12343", "mod11", true},
+		{"12344", "mod11", false},
+		{"1234560", "mod11", true},
+		{"60", "mod11", true},
+		{"51", "mod11", true},
+		{"12345", "なし", true},
+		{"1234A", "mod10", false},
+		{"1", "mod10", false},
+		{"", "mod11", false},
+	}
+	for _, tt := range tests {
+		if got := checkDigit(tt.s, tt.method); got != tt.want {
+			t.Errorf("checkDigit(%q, %q) = %v, want %v", tt.s, tt.method, got, tt.want)
+		}
+	}
+}
+
+// 全角・ハイフン・スペースをそろえ、ゼロ埋めは数字だけの番号に行う
+func TestSyainNorm(t *testing.T) {
+	zero := []string{"A001", "6", "数字", "する", "なし"}
+	tests := []struct {
+		s    string
+		rule []string
+		want string
+	}{
+		{"１２３", nil, "123"},
+		{"12-34 5", nil, "12345"},
+		{"123", zero, "000123"},
+		{"１２ ３", zero, "000123"},
+		{"1234567", zero, "1234567"},
+		{"A12", zero, "A12"},
+		{"123", []string{"*", "6", "数字", "しない", "なし"}, "123"},
+	}
+	for _, tt := range tests {
+		if got := syainNorm(tt.s, tt.rule); got != tt.want {
+			t.Errorf("syainNorm(%q, %v) = %q, want %q", tt.s, tt.rule, got, tt.want)
+		}
+	}
+}
+
+// 会社のルールがなければ「*」のルールを使う
+func TestSyainRuleFind(t *testing.T) {
+	moto := syainRule
+	defer func() { syainRule = moto }()
+
+	syainRule = [][]string{
+		{"*", "10", "数字", "しない", "なし"},
+		{"A001", "6", "数字", "する", "mod10"},
+	}
+	if got := syainRuleFind("A001"); got[0] != "A001" {
+		t.Errorf("syainRuleFind(A001) = %v", got)
+	}
+	if got := syainRuleFind("B002"); got[0] != "*" {
+		t.Errorf("syainRuleFind(B002) = %v", got)
+	}
+
+	syainRule = nil
+	if got := syainRuleFind("A001"); got != nil {
+		t.Errorf("ルールなし: syainRuleFind(A001) = %v", got)
+	}
+}
+
+func TestSyainRuleCheck(t *testing.T) {
+	suuji := []string{"*", "5", "数字", "しない", "mod11"}
+	eisuu := []string{"*", "4", "英数字", "しない", "なし"}
+	tests := []struct {
+		s    string
+		rule []string
+		want string
+	}{
+		{"", nil, "社員番号なし"},
+		{"", suuji, "社員番号なし"},
+		{"何でも", nil, ""},
+		{"12343", suuji, ""},
+		{"1234", suuji, "社員番号が5桁ではありません"},
+		{"1234A", suuji, "社員番号に使えない文字があります"},
+		{"12344", suuji, "社員番号のチェックデジットが違います"},
+		{"AB12", eisuu, ""},
+		{"AB-1", eisuu, "社員番号に使えない文字があります"},
+	}
+	for _, tt := range tests {
+		if got := syainRuleCheck(tt.s, tt.rule); got != tt.want {
+			t.Errorf("syainRuleCheck(%q, %v) = %q, want %q", tt.s, tt.rule, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 書式の確認
+// 保存する前に健診データのすべての行を、列毎の必須・型・値・最大文字数で確かめる
+// 誤りがあれば健診データを保存せずに処理を止める（-force で誤りがあっても出力する）
+var syoshikiForce = flag.Bool("force", false, "書式の確認で誤りがあっても健診データを出力する")
+
+// 列毎の書式（列, 必須とする健診区分, 型, 値, 最大文字数, 必須とする列, 形式）
+// 列は「1,3,98-107」のように書く。「36|37|38」はいずれかに値があれば必須を満たす列の組
+// 必須とする健診区分はカンマ区切りで、*はすべての行。事業者健診は健診データの本人の行、
+// 特定健診は特定健診の対象者（年度末に40～74歳）の健保組合提出用の行に当てはめる
+// 型は 文字・数値（値は「下限-上限」）・日付（yyyy/mm/dd）・コード（値はカンマ区切りの一覧）。空欄は必須だけを確かめる
+// 最大文字数が空欄なら文字数の設定（設定/文字数.txt）の最大文字数を使う
+// 必須とする列は「72,209」のように書き、そのいずれかに値があれば必須とする
+// 形式はカンマ区切りで、その形式（2017・2018・2021）で出力する行にだけ当てはめる（空欄はすべて）
+// 出力する形式にない列は確かめない。空欄は必須でなければ確かめない
+// 問診（176～205）の回答コードは、受診日の質問票の版毎に問診の設定（monshin）で確かめる
+var syoshiki = [][]string{
+	{"2", "*", "文字", "", "20", "", ""},                              // 受診者ID
+	{"12", "*", "コード", "本人,家族", "", "", ""},                         // 受診者区分
+	{"13", "*", "コード", "男,女", "", "", ""},                           // 性別
+	{"14", "*", "文字", "", "50", "", ""},                             // 氏名漢字
+	{"15", "", "文字", "", "50", "", ""},                              // 氏名カナ
+	{"16", "*", "日付", "", "", "", ""},                               // 生年月日
+	{"17", "*", "数値", "2000-2100", "", "", ""},                      // 実施年度
+	{"18", "", "数値", "0-120", "", "", ""},                           // 年齢
+	{"19", "*", "日付", "", "", "", ""},                               // 受診日
+	{"20", "*", "コード", "事業者健診,特定健診", "", "", ""},                    // 健診区分
+	{"21", "*", "文字", "", "", "", ""},                               // 医療機関コード
+	{"27", "特定健診", "数値", "100-250", "", "", ""},                     // 身長
+	{"28", "事業者健診,特定健診", "数値", "20-250", "", "", ""},                // 体重
+	{"29", "特定健診", "数値", "10-80", "", "", ""},                       // BMI
+	{"31", "特定健診", "数値", "40-250", "", "", ""},                      // 腹囲
+	{"33-35,70,72,149,175", "", "文字", "", "", "", ""},               // 既往歴・症状・所見・コメント・備考（文字数の設定）
+	{"36-38", "", "数値", "50-300", "", "", ""},                       // 収縮期血圧
+	{"39-41", "", "数値", "20-200", "", "", ""},                       // 拡張期血圧
+	{"36|37|38,39|40|41", "事業者健診,特定健診", "", "", "", "", ""},         // 血圧（いずれかの回）
+	{"44,45,48-50", "特定健診", "", "", "", "", ""},                     // 中性脂肪・HDL・肝機能
+	{"46|47", "特定健診", "", "", "", "", ""},                           // LDL・Non-HDLコレステロール
+	{"54|55|57", "特定健診", "", "", "", "", ""},                        // 血糖（空腹時・随時・HbA1c）
+	{"57", "", "数値", "3-20", "", "", ""},                            // HbA1c(NGSP)
+	{"58,59", "事業者健診,特定健診", "コード", "－,±,+,++,+++,++++", "", "", ""}, // 尿糖・尿蛋白
+	{"73", "", "日付", "", "", "72,209,210", ""},                      // 胸部X線検査(撮影年月日)
+	{"82-85", "", "コード", "所見なし,所見あり", "", "", ""},                   // 聴力
+	{"108-138,150,209-211", "", "コード", "A,B,C,D,E,F,G", "", "", ""}, // 判定
+	{"167", "事業者健診,特定健診", "", "", "", "", ""},                       // 医師の診断(判定)
+	{"176,179,182,189", "特定健診", "", "", "", "", ""},                 // 服薬・喫煙
+	{"212,213,220,221", "", "コード", "1,2,3,4,5,6,7", "", "", ""},     // 判定コード
+	{"214,215", "", "コード", "1,2,3,4,5,6", "", "", ""},               // 尿糖・尿蛋白コード
+	{"216-219", "", "コード", "1,2", "", "", ""},                       // 聴力コード
+}
+
+// 行に当てはめる健診区分（kubunは「健診データ」か「健保組合提出用」）
+func syoshikiKubun(cRec []string, kubun string) map[string]bool {
+	kubuns := map[string]bool{"*": true}
+	switch {
+	case kubun == "健診データ":
+		kubuns[cRec[20]] = true
+	case tokuteiCheck(cRec):
+		kubuns["特定健診"] = true
+	}
+	return kubuns
+}
+
+// 1人分の誤り（denaiは出力する形式にない列）
+func syoshikiCheck(cRec []string, head []string, kubun string, yName string, denai map[int]bool) []string {
+	kubuns := syoshikiKubun(cRec, kubun)
+	ayamari := make([]string, 0)
+	for _, s := range syoshiki {
+		if s[6] != "" {
+			ari := false
+			for _, y := range strings.Split(s[6], ",") {
+				if strings.TrimSpace(y) == yName {
+					ari = true
+				}
+			}
+			if !ari {
+				continue
+			}
+		}
+
+		hissu := false
+		for _, k := range strings.Split(s[1], ",") {
+			if kubuns[strings.TrimSpace(k)] {
+				hissu = true
+			}
+		}
+		for _, k := range colList(s[5]) {
+			if k < len(cRec) && cRec[k] != "" {
+				hissu = true
+			}
+		}
+
+		// 列の組（いずれかに値があればよい）と1列ずつの指定に分ける
+		kumi := make([][]int, 0)
+		for _, c := range strings.Split(s[0], ",") {
+			if strings.Contains(c, "|") {
+				kumi = append(kumi, colList(strings.Replace(c, "|", ",", -1)))
+			} else {
+				for _, i := range colList(c) {
+					kumi = append(kumi, []int{i})
+				}
+			}
+		}
+
+		for _, k := range kumi {
+			atai := false
+			dasu := false
+			for _, c := range k {
+				if c < len(cRec) && !denai[c] {
+					dasu = true
+					if cRec[c] != "" {
+						atai = true
+					}
+				}
+			}
+			if !dasu {
+				continue
+			}
+			if !atai {
+				if hissu {
+					names := make([]string, 0)
+					for _, c := range k {
+						names = append(names, strconv.Itoa(c)+"."+head[c])
+					}
+					ayamari = append(ayamari, strings.Join(names, "|")+":空欄")
+				}
+				continue
+			}
+
+			for _, c := range k {
+				if c >= len(cRec) || denai[c] || cRec[c] == "" {
+					continue
+				}
+				if a := syoshikiAtai(s, c, cRec[c]); a != "" {
+					ayamari = append(ayamari, strconv.Itoa(c)+"."+head[c]+":"+a)
+				}
+			}
+		}
+	}
+	return ayamari
+}
+
+// 1つの値の誤り（型・値・最大文字数）
+func syoshikiAtai(s []string, c int, v string) string {
+	ok := true
+	switch s[2] {
+	case "数値":
+		f, err := strconv.ParseFloat(v, 64)
+		ok = err == nil
+		if r := strings.SplitN(s[3], "-", 2); ok && len(r) == 2 {
+			from, err1 := strconv.ParseFloat(r[0], 64)
+			to, err2 := strconv.ParseFloat(r[1], 64)
+			ok = err1 != nil || err2 != nil || (f >= from && f <= to)
+		}
+	case "日付":
+		_, err := time.Parse("2006/01/02", v)
+		ok = err == nil
+	case "コード":
+		ok = false
+		for _, k := range strings.Split(s[3], ",") {
+			if v == strings.TrimSpace(k) {
+				ok = true
+			}
+		}
+	}
+	if !ok {
+		return v
+	}
+
+	maxLen, _ := strconv.Atoi(s[4])
+	if s[4] == "" {
+		maxLen = mojisuMax(c)
+	}
+	if maxLen > 0 && utf8.RuneCountInString(v) > maxLen {
+		return strconv.Itoa(maxLen) + "文字を超えています"
+	}
+	return ""
+}
+
+// 健診データのすべての行を確かめる（誤りがあれば記録し、-force がなければ保存させず最後に止める）
+func syoshikiKakunin(excelName string, kubun string, head []string, cRecs [][]string) bool {
+	// 形式毎に、出力しない列
+	denais := map[string]map[int]bool{}
+	for _, y := range yousikiList {
+		denai := map[int]bool{}
+		for i := range head {
+			denai[i] = true
+		}
+		for _, c := range yousikiCol(y, head) {
+			delete(denai, c)
+		}
+		denais[y.name] = denai
+	}
+
+	codes := monshinCodes()
+
+	kensu := 0
+	for _, cRec := range cRecs {
+		y := yousikiFind(cRec)
+		ayamari := syoshikiCheck(cRec, head, kubun, y.name, denais[y.name])
+		ayamari = append(ayamari, monshinKakunin(cRec, head, codes, denais[y.name])...)
+		ayamari = append(ayamari, yousikiDenai(cRec, head, y, denais[y.name])...)
+		if len(ayamari) == 0 {
+			continue
+		}
+		kensu++
+		log.Printf("書式の誤りがあります 受診者ID:%v %v\r\n", cRec[2], strings.Join(ayamari, " "))
+	}
+	if kensu == 0 {
+		return true
+	}
+
+	if *syoshikiForce {
+		log.Printf("書式の誤りがありますが -force のため出力します:%v %v件\r\n", excelName, kensu)
+		return true
+	}
+	kensyouErr(fmt.Sprintf("書式の誤りがあるため出力しませんでした:%v %v件", excelName, kensu))
+	return false
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestSyoshikiAtai(t *testing.T) {
+	moto := mojisu
+	defer func() { mojisu = moto }()
+	mojisu = [][]string{{"175", "5", "33"}}
+
+	tests := []struct {
+		s    []string
+		c    int
+		v    string
+		want string
+	}{
+		{[]string{"", "", "数値", "20-250", "", "", ""}, 28, "65.5", ""},
+		{[]string{"", "", "数値", "20-250", "", "", ""}, 28, "20", ""},
+		{[]string{"", "", "数値", "20-250", "", "", ""}, 28, "250", ""},
+		{[]string{"", "", "数値", "20-250", "", "", ""}, 28, "19.9", "19.9"},
+		{[]string{"", "", "数値", "20-250", "", "", ""}, 28, "六十", "六十"},
+		{[]string{"", "", "数値", "", "", "", ""}, 28, "1000", ""},
+		{[]string{"", "", "日付", "", "", "", ""}, 19, "2021/06/01", ""},
+		{[]string{"", "", "日付", "", "", "", ""}, 19, "2021-06-01", "2021-06-01"},
+		{[]string{"", "", "日付", "", "", "", ""}, 19, "2021/02/30", "2021/02/30"},
+		{[]string{"", "", "コード", "男, 女", "", "", ""}, 13, "女", ""},
+		{[]string{"", "", "コード", "男,女", "", "", ""}, 13, "2", "2"},
+		{[]string{"", "", "文字", "", "3", "", ""}, 14, "あいう", ""},
+		{[]string{"", "", "文字", "", "3", "", ""}, 14, "あいうえ", "3文字を超えています"},
+		{[]string{"", "", "文字", "", "", "", ""}, 175, "あいうえお", ""},
+		{[]string{"", "", "文字", "", "", "", ""}, 175, "あいうえおか", "5文字を超えています"},
+		{[]string{"", "", "文字", "", "", "", ""}, 14, "文字数の設定にない列", ""},
+	}
+	for _, tt := range tests {
+		if got := syoshikiAtai(tt.s, tt.c, tt.v); got != tt.want {
+			t.Errorf("syoshikiAtai(%v, %v, %q) = %q, want %q", tt.s, tt.c, tt.v, got, tt.want)
+		}
+	}
+}
+
+// 必須とする健診区分・列の組・必須とする列・形式・出力しない列の当てはめ
+func TestSyoshikiCheck(t *testing.T) {
+	moto := syoshiki
+	defer func() { syoshiki = moto }()
+	syoshiki = [][]string{
+		{"3", "事業者健診", "数値", "0-10", "", "", ""},
+		{"4|5", "事業者健診", "", "", "", "", ""},
+		{"6", "", "日付", "", "", "7", ""},
+		{"8", "*", "コード", "A,B", "", "", "2017"},
+	}
+
+	head := make([]string, 22)
+	for i := range head {
+		head[i] = "h" + strconv.Itoa(i)
+	}
+	rec := func(kv map[int]string) []string {
+		r := make([]string, 22)
+		r[3], r[4], r[8], r[20] = "5", "x", "A", "事業者健診"
+		for k, v := range kv {
+			r[k] = v
+		}
+		return r
+	}
+
+	tests := []struct {
+		name  string
+		cRec  []string
+		yName string
+		denai map[int]bool
+		want  []string
+	}{
+		{"誤りなし", rec(nil), "2017", nil, []string{}},
+		{"範囲外", rec(map[int]string{3: "11"}), "2021", nil, []string{"3.h3:11"}},
+		{"必須の空欄", rec(map[int]string{3: ""}), "2021", nil, []string{"3.h3:空欄"}},
+		{"必須でない健診区分", rec(map[int]string{3: "", 4: "", 20: "特定健診"}), "2021", nil, []string{}},
+		{"組のもう一方に値", rec(map[int]string{4: "", 5: "y"}), "2021", nil, []string{}},
+		{"組のすべてが空欄", rec(map[int]string{4: ""}), "2021", nil, []string{"4.h4|5.h5:空欄"}},
+		{"必須とする列に値", rec(map[int]string{7: "所見"}), "2021", nil, []string{"6.h6:空欄"}},
+		{"必須とする列に値・日付あり", rec(map[int]string{6: "2021/06/01", 7: "所見"}), "2021", nil, []string{}},
+		{"形式に当てはまる", rec(map[int]string{8: "C"}), "2017", nil, []string{"8.h8:C"}},
+		{"形式に当てはまらない", rec(map[int]string{8: "C"}), "2021", nil, []string{}},
+		{"出力しない列", rec(map[int]string{3: "", 4: ""}), "2021", map[int]bool{3: true, 4: true},
+			[]string{"4.h4|5.h5:空欄"}},
+		{"組のすべてを出力しない", rec(map[int]string{4: ""}), "2021", map[int]bool{4: true, 5: true}, []string{}},
+	}
+	for _, tt := range tests {
+		if got := syoshikiCheck(tt.cRec, head, "健診データ", tt.yName, tt.denai); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: syoshikiCheck = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
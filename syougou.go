@@ -0,0 +1,173 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/tealeg/xlsx"
+)
+
+// 受診状況の照合（-syougou 年度 で起動し、引数にその年度のA85パターンをいくつでも渡す）
+// 会社の人事名簿（設定/名簿）と受診者を照合し、受診済・未受診・名簿外受診者を所属毎に一覧にする
+var syougouNendo = flag.String("syougou", "", "人事名簿と照合する年度（例：2024）")
+
+// 照合結果の1人分
+type syougou struct {
+	syozok string
+	rec    []string
+}
+
+func syougouCreate(nen string, files []string) {
+	// 複数の抽出データをまとめる（項目名は最初の空でないファイルで決める）
+	records := make([][]string, 0)
+	for _, file := range files {
+		recs := readfile(file)
+		if len(recs) == 0 {
+			continue
+		}
+		if len(records) == 0 {
+			inHeadSet(recs[0])
+			records = append(records, recs[0])
+		}
+		n := 0
+		for _, inRec := range recs[1:] {
+			if nendo(inRec[15]) == nen {
+				records = append(records, inRec)
+				n++
+			}
+		}
+		log.Printf("受診データを読み込みました:%v (%v年度 %v件)\r\n", file, nen, n)
+	}
+	if len(records) < 2 {
+		log.Print(nen + "年度の受診者がいません\r\n")
+		return
+	}
+
+	outDir := dirCreate(files[0], "トヨタモビリティ東京")
+	day := time.Now()
+	for _, coRec := range coSurvey(records) {
+		list := jinjiLoad(coRec)
+		if len(list) == 0 {
+			log.Print("人事名簿がないため照合できません:" + coRec[1] + "\r\n")
+			continue
+		}
+
+		// 名簿と受診者の社員番号は会社の社員番号ルールでそろえて照合する
+		rule := syainRuleFind(coRec[0])
+		syains := map[string]jinji{}
+		for _, j := range list {
+			if s := syainNorm(j.syain, rule); s != "" {
+				syains[s] = j
+			}
+		}
+
+		// 受診者を名簿の方に結び付ける（社員番号、なければカナ・生年月日・氏名）
+		jusin := map[jinji][]string{}
+		gai := make([]syougou, 0)
+		for _, inRec := range records[1:] {
+			if inRec[4] != coRec[0] || !coseCheck(inRec[13]) || !honninCheck(inRec) {
+				continue
+			}
+
+			j, ok := syains[syainNorm(inRec[0], rule)]
+			if !ok {
+				cand := jinjiMatch(list, inRec[9], inRec[10], WaToSeireki(inRec[11]))
+				if len(cand) == 1 {
+					j, ok = cand[0], true
+				}
+			}
+
+			if !ok {
+				// 名簿外の方は名簿の所属がないので、所属別には名簿外としてまとめる（一覧には受診データの所属名称）
+				gai = append(gai, syougou{inRec[7], []string{inRec[7], inRec[0], inRec[9], inRec[10], WaToSeireki(inRec[11]), inRec[15], inRec[14], inRec[1]}})
+				continue
+			}
+			// 同じ年度に複数回受診していれば最初の受診日
+			if r, ari := jusin[j]; !ari || inRec[15] < r[0] {
+				jusin[j] = []string{inRec[15], inRec[14], inRec[1]}
+			}
+		}
+
+		zumi := make([]syougou, 0)
+		mi := make([]syougou, 0)
+		for _, j := range list {
+			rec := []string{j.syozok, j.syain, j.name, j.kana, j.birth}
+			if r, ari := jusin[j]; ari {
+				zumi = append(zumi, syougou{j.syozok, append(rec, r...)})
+			} else {
+				mi = append(mi, syougou{j.syozok, rec})
+			}
+		}
+
+		// 名簿の所属別の件数（名簿外受診者は最後の行にまとめる）
+		syozoks := make([]string, 0)
+		kensu := map[string][]int{}
+		count := func(list []syougou, k int) {
+			for _, s := range list {
+				if _, ari := kensu[s.syozok]; !ari {
+					syozoks = append(syozoks, s.syozok)
+					kensu[s.syozok] = make([]int, 2)
+				}
+				kensu[s.syozok][k]++
+			}
+		}
+		count(zumi, 0)
+		count(mi, 1)
+		sort.Strings(syozoks)
+
+		syukei := make([][]string, 0)
+		for _, s := range syozoks {
+			k := kensu[s]
+			ritsu := ""
+			if k[0]+k[1] > 0 {
+				ritsu = fmt.Sprintf("%.1f%%", float64(k[0])*100/float64(k[0]+k[1]))
+			}
+			syukei = append(syukei, []string{s, fmt.Sprint(k[0] + k[1]), fmt.Sprint(k[0]), fmt.Sprint(k[1]), ritsu, ""})
+		}
+		if len(gai) > 0 {
+			syukei = append(syukei, []string{"名簿外", "", "", "", "", fmt.Sprint(len(gai))})
+		}
+
+		meibo := []string{"所属", "社員番号", "氏名", "カナ", "生年月日"}
+		kensin := []string{"受診日", "コース", "受診者ID"}
+		excelName := outDir + coRec[1] + "受診状況照合" + nen + "年度" + day.Format("20060102") + ".xlsx"
+		syougouSave(excelName, []string{"所属別", "受診済", "未受診", "名簿外受診者"}, [][][]string{
+			append([][]string{{"所属", "名簿人数", "受診済", "未受診", "受診率", "名簿外受診者"}}, syukei...),
+			syougouRows(append(meibo, kensin...), zumi),
+			syougouRows(meibo, mi),
+			syougouRows(append([]string{"所属（受診データ）", "社員番号", "氏名", "カナ", "生年月日"}, kensin...), gai),
+		})
+		log.Printf("受診状況を照合しました:%v 受診済%v人 未受診%v人 名簿外%v人\r\n", coRec[1], len(zumi), len(mi), len(gai))
+	}
+}
+
+// 所属順に並べて項目名を付ける
+func syougouRows(head []string, list []syougou) [][]string {
+	sort.SliceStable(list, func(i, j int) bool { return list[i].syozok < list[j].syozok })
+	rows := [][]string{head}
+	for _, s := range list {
+		rows = append(rows, s.rec)
+	}
+	return rows
+}
+
+func syougouSave(excelName string, names []string, sheets [][][]string) {
+	excelFile := xlsx.NewFile()
+	xlsx.SetDefaultFont(11, "ＭＳ Ｐゴシック")
+	for i, name := range names {
+		sheet, err := excelFile.AddSheet(name)
+		failOnError(err)
+		for _, rec := range sheets[i] {
+			row := sheet.AddRow()
+			for _, cell := range rec {
+				row.AddCell().Value = cell
+			}
+		}
+	}
+
+	err := excelFile.Save(excelName)
+	failOnError(err)
+}
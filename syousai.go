@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// 詳細な健診（特定健診）の実施理由
+// 　貧血検査：貧血の既往歴を有する者又は視診等で貧血が疑われる者
+// 　心電図検査：当該年度の健診結果等において、収縮期血圧140mmHg以上若しくは拡張期血圧90mmHg以上の者
+// 　　　　　　　又は問診等で不整脈が疑われる者
+// 　眼底検査：当該年度の健診結果等において、血圧が心電図と同じ基準の者
+// 　　　　　　又は空腹時血糖126mg/dl以上、HbA1c6.5%以上若しくは随時血糖126mg/dl以上の者
+// 　　　　　　（当該年度の血糖検査の結果がない場合は前年度の結果で判断する）
+
+// 眼底検査の分類（出力列, A85パターンの項目名, 受け付ける値）
+var ganteiBunrui = [][]string{
+	{"87", "キースワグナー分類", "0,I,IIa,IIb,III,IV"},
+	{"88", "シェイエ分類H", "0,1,2,3,4"},
+	{"89", "シェイエ分類S", "0,1,2,3,4"},
+	{"90", "SCOTT分類", "0,I(a),I(b),II,III(a),III(b),IV,V(a),V(b),VI"},
+	{"91", "Wong-Mitchell分類", "所見なし,軽度,中等度,重度"},
+	{"92", "改変Davis分類", "SDRなし,SDR,PPDR,PDR"},
+}
+
+func atof(s string) (float64, bool) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(string(norm.NFKC.Bytes([]byte(s)))), 64)
+	return v, err == nil
+}
+
+// 血圧は2回測定していれば平均を使う
+func ketsuatsu(s1, s2 string) (float64, bool) {
+	v1, ok1 := atof(s1)
+	v2, ok2 := atof(s2)
+	switch {
+	case ok1 && ok2:
+		return (v1 + v2) / 2, true
+	case ok1:
+		return v1, true
+	case ok2:
+		return v2, true
+	}
+	return 0, false
+}
+
+func ketsuatsuRiyu(cRec []string) string {
+	riyu := make([]string, 0)
+	if v, ok := ketsuatsu(cRec[38], cRec[37]); ok && v >= 140 {
+		riyu = append(riyu, "収縮期血圧"+fmt.Sprint(v)+"mmHg")
+	}
+	if v, ok := ketsuatsu(cRec[41], cRec[40]); ok && v >= 90 {
+		riyu = append(riyu, "拡張期血圧"+fmt.Sprint(v)+"mmHg")
+	}
+	return strings.Join(riyu, " ")
+}
+
+// 血糖の基準。当該年度の結果がなければ前年度の結果（A85パターンの前年度項目）で判断する
+func kettouRiyu(cRec []string, inRec []string) string {
+	riyu := make([]string, 0)
+	check := func(name, s, unit string, limit float64) bool {
+		v, ok := atof(s)
+		if ok && v >= limit {
+			riyu = append(riyu, name+strings.TrimSpace(s)+unit)
+		}
+		return ok
+	}
+
+	ari := false
+	ari = check("空腹時血糖", cRec[54], "mg/dl", 126) || ari
+	ari = check("HbA1c", cRec[57], "%", 6.5) || ari
+	ari = check("随時血糖", cRec[55], "mg/dl", 126) || ari
+	if !ari {
+		check("前年度空腹時血糖", inVal(inRec, "前年度空腹時血糖"), "mg/dl", 126)
+		check("前年度HbA1c", inVal(inRec, "前年度HbA1c"), "%", 6.5)
+		check("前年度随時血糖", inVal(inRec, "前年度随時血糖"), "mg/dl", 126)
+	}
+	return strings.Join(riyu, " ")
+}
+
+// 66.貧血検査実施理由
+func hinketsuRiyu(cRec []string, kiouCodes []string) string {
+	for _, code := range kiouCodes {
+		if code >= "D50" && code < "D65" {
+			return "貧血の既往歴"
+		}
+	}
+	if yesNo(cRec[188]) == "はい" {
+		return "貧血の既往歴"
+	}
+	if strings.Contains(cRec[35], "貧血") {
+		return "視診等で貧血の疑い"
+	}
+	return ""
+}
+
+// 71.心電図(実施理由)
+func ecgRiyu(cRec []string, kiouCodes []string) string {
+	if riyu := ketsuatsuRiyu(cRec); riyu != "" {
+		return riyu
+	}
+	for _, code := range kiouCodes {
+		if strings.HasPrefix(code, "I47") || strings.HasPrefix(code, "I48") || strings.HasPrefix(code, "I49") {
+			return "問診等で不整脈の疑い"
+		}
+	}
+	if strings.Contains(cRec[34], "動悸") {
+		return "問診等で不整脈の疑い"
+	}
+	return ""
+}
+
+// 94.眼底検査(実施理由)
+func ganteiRiyu(cRec []string, inRec []string) string {
+	riyu := ketsuatsuRiyu(cRec)
+	if k := kettouRiyu(cRec, inRec); k != "" {
+		riyu = strings.Trim(riyu+" "+k, " ")
+	}
+	return riyu
+}
+
+// ローマ数字・記号の表記をそろえる（Ⅱa→IIa、2a→IIa、H2→2 など）
+func ganteiNorm(s string, col string) string {
+	s = strings.TrimSpace(string(norm.NFKC.Bytes([]byte(s))))
+	s = strings.Replace(s, " ", "", -1)
+	s = strings.Replace(s, "（", "(", -1)
+	s = strings.Replace(s, "）", ")", -1)
+
+	switch col {
+	case "87", "90":
+		roman := []string{"VI", "V", "IV", "III", "II", "I"}
+		arabic := []string{"6", "5", "4", "3", "2", "1"}
+		for i, a := range arabic {
+			if strings.HasPrefix(s, a) {
+				s = roman[i] + s[len(a):]
+				break
+			}
+		}
+		s = strings.Replace(s, "A", "a", -1)
+		s = strings.Replace(s, "B", "b", -1)
+		if col == "90" && len(s) > 0 && (strings.HasSuffix(s, "a") || strings.HasSuffix(s, "b")) && !strings.HasSuffix(s, ")") {
+			s = s[:len(s)-1] + "(" + s[len(s)-1:] + ")"
+		}
+	case "88", "89":
+		s = strings.TrimLeft(s, "HShs")
+	}
+	return s
+}
+
+// 87～93.眼底検査の分類（入力にない分類は空欄、受け付けない値は記録してそのまま出力）
+func ganteiSet(cRec []string, inRec []string) bool {
+	jisshi := false
+	for _, g := range ganteiBunrui {
+		c, _ := strconv.Atoi(g[0])
+		v := inVal(inRec, g[1])
+		if v == "" {
+			continue
+		}
+
+		jisshi = true
+		v = ganteiNorm(v, g[0])
+		ok := false
+		for _, a := range strings.Split(g[2], ",") {
+			if v == a {
+				ok = true
+			}
+		}
+		if !ok {
+			log.Printf("眼底検査の%vが正しくありません 受診者ID:%v 値:%v\r\n", g[1], inRec[1], v)
+		}
+		cRec[c] = v
+	}
+
+	// 93.眼底検査(その他の所見)
+	cRec[93] = syoken(inVal(inRec, "眼底その他所見", "眼底検査その他の所見"))
+	if cRec[93] != "" {
+		jisshi = true
+	}
+
+	return jisshi
+}
+
+// 安衛法の定期健康診断で必ず行う年齢か（35歳・40歳以上は貧血検査・心電図を省略できない）
+func anneiHissu(cRec []string) bool {
+	if cRec[12] != "本人" {
+		return false
+	}
+	age, err := strconv.Atoi(cRec[18])
+	return err == nil && (age == 35 || age >= 40)
+}
+
+// 詳細な健診の実施理由を設定し、基準に該当しないのに実施したものを記録する
+// 詳細な健診は特定健診の対象者（年度末に40～74歳）だけの基準なので、それ以外の方は理由を付けない
+// 安衛法で必ず行う貧血検査・心電図は詳細な健診ではないので理由を付けない
+func syousaiSet(cRec []string, inRec []string, kiouCodes []string) {
+	gantei := ganteiSet(cRec, inRec)
+	if !tokuteiCheck(cRec) {
+		return
+	}
+	hourei := anneiHissu(cRec)
+
+	check := func(name string, jisshi bool, col int, riyu string) {
+		if !jisshi {
+			return
+		}
+		if riyu == "" {
+			log.Printf("詳細な健診の基準に該当しないまま実施しています 受診者ID:%v %v\r\n", inRec[1], name)
+			return
+		}
+		cRec[col] = riyu
+	}
+
+	if !hourei {
+		check("貧血検査", cRec[63] != "" || cRec[64] != "" || cRec[65] != "", 66, hinketsuRiyu(cRec, kiouCodes))
+		check("心電図", cRec[70] != "" || cRec[211] != "", 71, ecgRiyu(cRec, kiouCodes))
+	}
+	check("眼底検査", gantei, 94, ganteiRiyu(cRec, inRec))
+}
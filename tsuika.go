@@ -0,0 +1,63 @@
+package main
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// 追加項目（コース, 番号1～10, 出力する項目名, A85パターンの値の項目名, 判定の項目名）
+// 追加項目はコース毎に設定し、値は「項目名:値」、判定は追加項目判定へ出力する
+var tsuikaKoumoku = [][]string{
+	{"95001001000401", "1", "CEA", "CEA", "CEA判定"},
+	{"95001001000401", "2", "CA19-9", "CA19-9", "CA19-9判定"},
+	{"95001001000401", "3", "PSA", "PSA", "PSA判定"},
+	{"95001001000401", "4", "腹部超音波", "腹部超音波所見", "腹部超音波判定"},
+	{"95001001000401", "5", "胃部X線", "胃部X線所見", "胃部X線判定"},
+	{"95001001000401", "6", "眼圧", "眼圧", "眼圧判定"},
+	{"95001001000402", "1", "CEA", "CEA", "CEA判定"},
+	{"95001001000402", "2", "CA19-9", "CA19-9", "CA19-9判定"},
+	{"95001001000402", "3", "PSA", "PSA", "PSA判定"},
+	{"95001001000402", "4", "腹部超音波", "腹部超音波所見", "腹部超音波判定"},
+	{"95001001000402", "5", "胃内視鏡", "胃内視鏡所見", "胃内視鏡判定"},
+	{"95001001000402", "6", "胃部X線", "胃部X線所見", "胃部X線判定"},
+	{"95001001000402", "7", "眼圧", "眼圧", "眼圧判定"},
+	{"95001001000402", "8", "肺機能", "肺機能", "肺機能判定"},
+	{"95001001000402", "9", "HBs抗原", "HBs抗原", "HBs抗原判定"},
+	{"95001001000402", "10", "HCV抗体", "HCV抗体", "HCV抗体判定"},
+}
+
+// 入力にない項目名の警告は1回だけにする
+var tsuikaNashi = map[string]bool{}
+
+// 98～107.追加項目1～10、139～148.追加項目判定1～10
+func tsuikaSet(cRec []string, inRec []string) {
+	for _, t := range tsuikaKoumoku {
+		if t[0] != inRec[13] {
+			continue
+		}
+
+		n, err := strconv.Atoi(t[1])
+		if err != nil || n < 1 || n > 10 {
+			log.Printf("追加項目の番号が正しくありません:%v\r\n", t)
+			continue
+		}
+
+		if !inHas(t[3]) {
+			if !tsuikaNashi[t[3]] {
+				log.Printf("追加項目の「%v」がデータ抽出にありません コース:%v\r\n", t[3], t[0])
+				tsuikaNashi[t[3]] = true
+			}
+			continue
+		}
+
+		v := syoken(inVal(inRec, t[3]))
+		if v == "" {
+			continue
+		}
+		cRec[97+n] = t[2] + ":" + v
+		cRec[138+n] = strings.TrimSpace(string(norm.NFKC.Bytes([]byte(inVal(inRec, t[4])))))
+	}
+}
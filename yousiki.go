@@ -0,0 +1,280 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 健診データの形式（トヨタ販売健保の定健フォーマットの版）
+// 健診データは2021年形式で作り、保存するときに受診者の実施年度の形式（-format で指定もできる）に並べ替える
+// 形式は3行目の項目名の並びで持ち、2021年形式の同じ項目名の列（同じ項目名が複数あれば出てきた順）を使う
+// 新しい形式は、2021年形式の列を増やしてからこの一覧の先頭に足す（古い形式の項目名は変えない）
+var yousikiFormat = flag.String("format", "", "健診データの形式（2017・2018・2021。指定しなければ受診日の年度で選ぶ）")
+
+type yousiki struct {
+	name    string     // 形式名
+	nendo   string     // この年度の受診から使う（空欄は最も古い形式）
+	koumoku []string   // 3行目の項目名（2021年形式の項目名）
+	title   [][]string // タイトルの変更（行, この形式の列, 値）
+	hokan   [][]string // 空欄のときに代わりに使う項目（この形式の列, 2021年形式の項目名）
+}
+
+// 新しい形式から並べる
+var yousikiList = []yousiki{
+	// 2021年度版（10項目目に社員番号）
+	{"2021", "2021", []string{
+		"#従業員番号", "組合コード", "受診者ID", "保険証記号", "保険証番号", "続柄",
+		"枝番", "所属コード", "所属名称", "社員番号", "加入番号", "扶養番号",
+		"受診者区分", "性別", "氏名漢字", "氏名カナ", "生年月日", "実施年度",
+		"年齢", "受診日", "健診区分", "医療機関コード", "医療機関名称", "機関コード",
+		"機関名称", "機関住所", "受付NO", "身長", "体重", "BMI",
+		"内臓脂肪面積", "腹囲", "業務歴", "既往歴", "自覚症状", "他覚症状",
+		"収縮期血圧(その他)", "収縮期血圧(２回目)", "収縮期血圧(１回目)", "拡張期血圧(その他)", "拡張期血圧(２回目)", "拡張期血圧(１回目)",
+		"採血時間", "総コレステロール", "中性脂肪", "HDLコレステロール", "LDLコレステロール", "NON-HDLコレステロール",
+		"GOT(AST)", "GPT(ALT)", "γ-GT(γ-GTP)", "血清クレアチニン", "eGFR", "血清尿酸",
+		"空腹時血糖", "随時血糖", "HbA1c", "HbA1c(NGSP)", "尿糖", "尿蛋白",
+		"尿潜血", "尿素窒素", "尿ウロビリノーゲン", "ヘマトクリット値", "血色素量(ヘモグロビン値)", "赤血球数",
+		"貧血検査実施理由", "白血球数", "血小板数", "血清アミラーゼ", "心電図(所見)", "心電図(実施理由)",
+		"胸部X線検査(所見)", "胸部X線検査(撮影年月日)", "喀痰検査(塗抹鏡検 一般細菌)(所見)", "喀痰検査(塗抹鏡検 抗酸菌)", "喀痰検査(ガフキー号数)", "便潜血",
+		"視力(裸眼右)", "視力(矯正右)", "視力(裸眼左)", "視力(矯正左)", "聴力(右1000Hz)", "聴力(右4000Hz)",
+		"聴力(左1000Hz)", "聴力(左4000Hz)", "聴力(その他の所見)", "眼底検査(キースワグナー分類)", "眼底検査(シェイエ分類:H)", "眼底検査(シェイエ分類:S)",
+		"眼底検査(SCOTT分類)", "眼底検査（wong-Mitchell分類）", "眼底検査（改変Davis分類）", "眼底検査(その他の所見)", "眼底検査(実施理由)", "その他の法定特殊健康診断",
+		"その他の法定検査", "その他の検査", "追加項目1", "追加項目2", "追加項目3", "追加項目4",
+		"追加項目5", "追加項目6", "追加項目7", "追加項目8", "追加項目9", "追加項目10",
+		"BMI判定", "内臓脂肪面積判定", "腹囲判定", "血圧判定", "総コレステロール判定", "中性脂肪判定",
+		"HDLコレステロール判定", "LDLコレステロール判定", "NON-HDLコレステロール判定", "GOT(AST)判定", "GPT(ALT)判定", "γ-GT(γ-GTP)判定",
+		"血清クレアチニン判定", "eGFR判定", "血清尿酸判定", "空腹時血糖判定", "随時血糖判定", "HbA1c判定",
+		"HbA1c（NGSP)判定", "尿糖判定", "尿蛋白判定", "尿潜血判定", "尿素窒素判定", "尿ウロビリノーゲン判定",
+		"ヘマトクリット値判定", "血色素量(ヘモグロビン値)判定", "赤血球数判定", "白血球数判定", "血小板数判定", "視力(右)判定",
+		"視力(左)判定", "追加項目判定1", "追加項目判定2", "追加項目判定3", "追加項目判定4", "追加項目判定5",
+		"追加項目判定6", "追加項目判定7", "追加項目判定8", "追加項目判定9", "追加項目判定10", "コメント",
+		"総合判定", "受診勧奨区分", "指導状態", "再検査区分", "一次健診日", "結果通知区分",
+		"メタボリック判定(血圧リスク)", "メタボリック判定(血糖リスク)", "メタボリック判定(脂質リスク)", "メタボリック判定(リスクカウント)", "支援レベル(血圧リスク)", "支援レベル(血糖リスク)",
+		"支援レベル(脂質リスク)", "支援レベル(喫煙リスク)", "支援レベル(リスクカウント)", "メタボリックシンドローム判定", "支援レベル", "医師の診断(判定)",
+		"健康診断を実施した医師の氏名", "医師の意見", "意見を述べた医師の氏名", "歯科医師による健康診断", "歯科医師による健康診断を実施した歯科医師の氏名", "歯科医師の意見",
+		"意見を述べた歯科医師の氏名", "備考", "服薬１_血圧", "血圧_薬剤", "血圧_服薬理由", "服薬２_血糖",
+		"血糖_薬剤", "血糖_服薬理由", "服薬３_脂質", "脂質_薬剤", "脂質_服薬理由", "既往歴１_脳血管",
+		"既往歴２_心血管", "既往歴３_腎不全人工透析", "貧血", "喫煙", "２０歳からの体重変化", "３０分以上の運動習慣",
+		"歩行又は身体活動", "歩行速度", "１年間の体重変化", "食事についての咀嚼", "食べ方１_早食い等", "食べ方２_就寝前",
+		"食べ方３_夜食間食", "食べ方３_三食以外の間食", "食習慣", "飲酒", "飲酒量", "睡眠",
+		"生活習慣の改善", "保健指導の希望", "報告対象区分", "保健指導からの除外", "取込年月日", "胸部X線判定①",
+		"胸部X線判定②", "心電図判定", "胸部レントゲン検査", "胸部レントゲン判定", "尿糖", "尿蛋白",
+		"聴力(右1000Hz)", "聴力(右4000Hz)", "聴力(左1000Hz)", "聴力(左4000Hz)", "心電図検査", "心電図判定",
+	}, nil, nil},
+
+	// 2018年度版（巡回健診フォーマット_松英会2018年度.xlsx）
+	{"2018", "2018", []string{
+		"#従業員番号", "組合コード", "受診者ID", "保険証記号", "保険証番号", "続柄",
+		"枝番", "所属コード", "所属名称", "加入番号", "扶養番号", "受診者区分",
+		"性別", "氏名漢字", "氏名カナ", "生年月日", "実施年度", "年齢",
+		"受診日", "健診区分", "医療機関コード", "医療機関名称", "機関コード", "機関名称",
+		"機関住所", "受付NO", "身長", "体重", "BMI", "内臓脂肪面積",
+		"腹囲", "業務歴", "既往歴", "自覚症状", "他覚症状", "収縮期血圧(その他)",
+		"収縮期血圧(２回目)", "収縮期血圧(１回目)", "拡張期血圧(その他)", "拡張期血圧(２回目)", "拡張期血圧(１回目)", "採血時間",
+		"総コレステロール", "中性脂肪", "HDLコレステロール", "LDLコレステロール", "NON-HDLコレステロール", "GOT(AST)",
+		"GPT(ALT)", "γ-GT(γ-GTP)", "血清クレアチニン", "eGFR", "血清尿酸", "空腹時血糖",
+		"随時血糖", "HbA1c", "HbA1c(NGSP)", "尿糖", "尿蛋白", "尿潜血",
+		"尿素窒素", "尿ウロビリノーゲン", "ヘマトクリット値", "血色素量(ヘモグロビン値)", "赤血球数", "貧血検査実施理由",
+		"白血球数", "血小板数", "血清アミラーゼ", "心電図(所見)", "心電図(実施理由)", "胸部X線検査(所見)",
+		"胸部X線検査(撮影年月日)", "喀痰検査(塗抹鏡検 一般細菌)(所見)", "喀痰検査(塗抹鏡検 抗酸菌)", "喀痰検査(ガフキー号数)", "便潜血", "視力(裸眼右)",
+		"視力(矯正右)", "視力(裸眼左)", "視力(矯正左)", "聴力(右1000Hz)", "聴力(右4000Hz)", "聴力(左1000Hz)",
+		"聴力(左4000Hz)", "聴力(その他の所見)", "眼底検査(キースワグナー分類)", "眼底検査(シェイエ分類:H)", "眼底検査(シェイエ分類:S)", "眼底検査(SCOTT分類)",
+		"眼底検査（wong-Mitchell分類）", "眼底検査（改変Davis分類）", "眼底検査(その他の所見)", "眼底検査(実施理由)", "その他の法定特殊健康診断", "その他の法定検査",
+		"その他の検査", "追加項目1", "追加項目2", "追加項目3", "追加項目4", "追加項目5",
+		"追加項目6", "追加項目7", "追加項目8", "追加項目9", "追加項目10", "BMI判定",
+		"内臓脂肪面積判定", "腹囲判定", "血圧判定", "総コレステロール判定", "中性脂肪判定", "HDLコレステロール判定",
+		"LDLコレステロール判定", "NON-HDLコレステロール判定", "GOT(AST)判定", "GPT(ALT)判定", "γ-GT(γ-GTP)判定", "血清クレアチニン判定",
+		"eGFR判定", "血清尿酸判定", "空腹時血糖判定", "随時血糖判定", "HbA1c判定", "HbA1c（NGSP)判定",
+		"尿糖判定", "尿蛋白判定", "尿潜血判定", "尿素窒素判定", "尿ウロビリノーゲン判定", "ヘマトクリット値判定",
+		"血色素量(ヘモグロビン値)判定", "赤血球数判定", "白血球数判定", "血小板数判定", "視力(右)判定", "視力(左)判定",
+		"追加項目判定1", "追加項目判定2", "追加項目判定3", "追加項目判定4", "追加項目判定5", "追加項目判定6",
+		"追加項目判定7", "追加項目判定8", "追加項目判定9", "追加項目判定10", "コメント", "総合判定",
+		"受診勧奨区分", "指導状態", "再検査区分", "一次健診日", "結果通知区分", "メタボリック判定(血圧リスク)",
+		"メタボリック判定(血糖リスク)", "メタボリック判定(脂質リスク)", "メタボリック判定(リスクカウント)", "支援レベル(血圧リスク)", "支援レベル(血糖リスク)", "支援レベル(脂質リスク)",
+		"支援レベル(喫煙リスク)", "支援レベル(リスクカウント)", "メタボリックシンドローム判定", "支援レベル", "医師の診断(判定)", "健康診断を実施した医師の氏名",
+		"医師の意見", "意見を述べた医師の氏名", "歯科医師による健康診断", "歯科医師による健康診断を実施した歯科医師の氏名", "歯科医師の意見", "意見を述べた歯科医師の氏名",
+		"備考", "服薬１_血圧", "血圧_薬剤", "血圧_服薬理由", "服薬２_血糖", "血糖_薬剤",
+		"血糖_服薬理由", "服薬３_脂質", "脂質_薬剤", "脂質_服薬理由", "既往歴１_脳血管", "既往歴２_心血管",
+		"既往歴３_腎不全人工透析", "貧血", "喫煙", "２０歳からの体重変化", "３０分以上の運動習慣", "歩行又は身体活動",
+		"歩行速度", "１年間の体重変化", "食事についての咀嚼", "食べ方１_早食い等", "食べ方２_就寝前", "食べ方３_夜食間食",
+		"食べ方３_三食以外の間食", "食習慣", "飲酒", "飲酒量", "睡眠", "生活習慣の改善",
+		"保健指導の希望", "報告対象区分", "保健指導からの除外", "取込年月日", "胸部X線判定①", "胸部X線判定②",
+		"心電図判定", "胸部レントゲン検査", "胸部レントゲン判定", "尿糖", "尿蛋白", "聴力(右1000Hz)",
+		"聴力(右4000Hz)", "聴力(左1000Hz)", "聴力(左4000Hz)", "心電図検査", "心電図判定",
+	}, [][]string{{"0", "50", "knk_kenkork_kensa.kensa_val_080"}, {"1", "0", "社員番号"}, {"2", "0", "従業員番号"}}, nil},
+
+	// 2017年版（定期健診フォーマット_松英会20170906.xlsx）。NON-HDL・eGFR・眼底の分類・咀嚼・間食がない
+	{"2017", "", []string{
+		"#従業員番号", "組合コード", "受診者ID", "保険証記号", "保険証番号", "続柄",
+		"枝番", "所属コード", "所属名称", "加入番号", "扶養番号", "受診者区分",
+		"性別", "氏名漢字", "氏名カナ", "生年月日", "実施年度", "年齢",
+		"受診日", "健診区分", "医療機関コード", "医療機関名称", "機関コード", "機関名称",
+		"機関住所", "受付NO", "身長", "体重", "BMI", "内臓脂肪面積",
+		"腹囲", "業務歴", "既往歴", "自覚症状", "他覚症状", "収縮期血圧(その他)",
+		"収縮期血圧(２回目)", "収縮期血圧(１回目)", "拡張期血圧(その他)", "拡張期血圧(２回目)", "拡張期血圧(１回目)", "採血時間",
+		"総コレステロール", "中性脂肪", "HDLコレステロール", "LDLコレステロール", "GOT(AST)", "GPT(ALT)",
+		"γ-GT(γ-GTP)", "血清クレアチニン", "血清尿酸", "空腹時血糖", "随時血糖", "HbA1c",
+		"HbA1c(NGSP)", "尿糖", "尿蛋白", "尿潜血", "尿素窒素", "尿ウロビリノーゲン",
+		"ヘマトクリット値", "血色素量(ヘモグロビン値)", "赤血球数", "貧血検査実施理由", "白血球数", "血小板数",
+		"血清アミラーゼ", "心電図(所見)", "心電図(実施理由)", "胸部X線検査(所見)", "胸部X線検査(撮影年月日)", "喀痰検査(塗抹鏡検 一般細菌)(所見)",
+		"喀痰検査(塗抹鏡検 抗酸菌)", "喀痰検査(ガフキー号数)", "便潜血", "視力(裸眼右)", "視力(矯正右)", "視力(裸眼左)",
+		"視力(矯正左)", "聴力(右1000Hz)", "聴力(右4000Hz)", "聴力(左1000Hz)", "聴力(左4000Hz)", "聴力(その他の所見)",
+		"眼底検査(キースワグナー分類)", "眼底検査(シェイエ分類:H)", "眼底検査(シェイエ分類:S)", "眼底検査(SCOTT分類)", "眼底検査(その他の所見)", "眼底検査(実施理由)",
+		"その他の法定特殊健康診断", "その他の法定検査", "その他の検査", "追加項目1", "追加項目2", "追加項目3",
+		"追加項目4", "追加項目5", "追加項目6", "追加項目7", "追加項目8", "追加項目9",
+		"追加項目10", "BMI判定", "内臓脂肪面積判定", "腹囲判定", "血圧判定", "総コレステロール判定",
+		"中性脂肪判定", "HDLコレステロール判定", "LDLコレステロール判定", "GOT(AST)判定", "GPT(ALT)判定", "γ-GT(γ-GTP)判定",
+		"血清クレアチニン判定", "血清尿酸判定", "空腹時血糖判定", "随時血糖判定", "HbA1c判定", "HbA1c（NGSP)判定",
+		"尿糖判定", "尿蛋白判定", "尿潜血判定", "尿素窒素判定", "尿ウロビリノーゲン判定", "ヘマトクリット値判定",
+		"血色素量(ヘモグロビン値)判定", "赤血球数判定", "白血球数判定", "血小板数判定", "視力(右)判定", "視力(左)判定",
+		"追加項目判定1", "追加項目判定2", "追加項目判定3", "追加項目判定4", "追加項目判定5", "追加項目判定6",
+		"追加項目判定7", "追加項目判定8", "追加項目判定9", "追加項目判定10", "コメント", "総合判定",
+		"受診勧奨区分", "指導状態", "再検査区分", "一次健診日", "結果通知区分", "メタボリック判定(血圧リスク)",
+		"メタボリック判定(血糖リスク)", "メタボリック判定(脂質リスク)", "メタボリック判定(リスクカウント)", "支援レベル(血圧リスク)", "支援レベル(血糖リスク)", "支援レベル(脂質リスク)",
+		"支援レベル(喫煙リスク)", "支援レベル(リスクカウント)", "メタボリックシンドローム判定", "支援レベル", "医師の診断(判定)", "健康診断を実施した医師の氏名",
+		"医師の意見", "意見を述べた医師の氏名", "歯科医師による健康診断", "歯科医師による健康診断を実施した歯科医師の氏名", "歯科医師の意見", "意見を述べた歯科医師の氏名",
+		"備考", "服薬１_血圧", "血圧_薬剤", "血圧_服薬理由", "服薬２_血糖", "血糖_薬剤",
+		"血糖_服薬理由", "服薬３_脂質", "脂質_薬剤", "脂質_服薬理由", "既往歴１_脳血管", "既往歴２_心血管",
+		"既往歴３_腎不全人工透析", "貧血", "喫煙", "２０歳からの体重変化", "３０分以上の運動習慣", "歩行又は身体活動",
+		"歩行速度", "１年間の体重変化", "食べ方１_早食い等", "食べ方２_就寝前", "食べ方３_夜食間食", "食習慣",
+		"飲酒", "飲酒量", "睡眠", "生活習慣の改善", "保健指導の希望", "報告対象区分",
+		"保健指導からの除外", "取込年月日", "胸部X線判定①", "胸部X線判定②", "心電図判定", "胸部レントゲン検査",
+		"胸部レントゲン判定", "尿糖", "尿蛋白", "聴力(右1000Hz)", "聴力(右4000Hz)", "聴力(左1000Hz)",
+		"聴力(左4000Hz)", "心電図検査", "心電図判定",
+	}, [][]string{{"0", "49", ""}, {"1", "49", ""}, {"1", "0", "社員番号"}, {"2", "0", "社員番号"},
+		{"2", "200", "胸部X線判定"}, {"2", "201", "胸部判定アルファベット"}, {"2", "202", "心電図判定アルファベット"}},
+		// 胸部判定アルファベットは一つだけなので、二次読影をしていなければ一次読影の判定
+		[][]string{{"201", "胸部X線判定①"}}},
+}
+
+// 受診者の健診データの形式（-format があればその形式）
+func yousikiFind(cRec []string) yousiki {
+	if *yousikiFormat != "" {
+		for _, y := range yousikiList {
+			if y.name == *yousikiFormat {
+				return y
+			}
+		}
+		log.Fatal("Error:健診データの形式がありません:" + *yousikiFormat + "\r\n")
+	}
+
+	for _, y := range yousikiList {
+		if cRec != nil && cRec[17] >= y.nendo {
+			return y
+		}
+	}
+	if cRec == nil {
+		return yousikiList[0]
+	}
+	return yousikiList[len(yousikiList)-1]
+}
+
+// 形式の列毎の2021年形式の列
+func yousikiCol(y yousiki, head []string) []int {
+	cols := make([]int, 0)
+	kazu := map[string]int{}
+	for _, k := range y.koumoku {
+		kazu[k]++
+		n := 0
+		c := -1
+		for i, h := range head {
+			if h == k {
+				n++
+				if n == kazu[k] {
+					c = i
+					break
+				}
+			}
+		}
+		if c < 0 {
+			log.Fatal("Error:" + y.name + "年形式の項目が2021年形式にありません:" + k + "\r\n")
+		}
+		cols = append(cols, c)
+	}
+	return cols
+}
+
+// 形式にない列に値があれば誤り（出力すると値が落ちるため。denaiは形式にない列）
+// 9.社員番号は0.社員番号と同じ値なら落ちても構わない
+func yousikiDenai(cRec []string, head []string, y yousiki, denai map[int]bool) []string {
+	ayamari := make([]string, 0)
+	for c := range cRec {
+		if !denai[c] || cRec[c] == "" || (c == 9 && cRec[9] == cRec[0]) {
+			continue
+		}
+		ayamari = append(ayamari, strconv.Itoa(c)+"."+head[c]+":"+y.name+"年形式にない列に値があります")
+	}
+	return ayamari
+}
+
+// 2021年形式の行を形式の列に並べ替える
+func yousikiRec(cRec []string, cols []int) []string {
+	rec := make([]string, len(cols))
+	for i, c := range cols {
+		rec[i] = cRec[c]
+	}
+	return rec
+}
+
+// 空欄の列に代わりの項目の値を入れる
+func yousikiHokan(y yousiki, head []string, cRec []string, rec []string) []string {
+	for _, h := range y.hokan {
+		c, err := strconv.Atoi(h[0])
+		if err != nil || c >= len(rec) || rec[c] != "" {
+			continue
+		}
+		for i, k := range head {
+			if k == h[1] {
+				rec[c] = cRec[i]
+				break
+			}
+		}
+	}
+	return rec
+}
+
+func yousikiHead(y yousiki, head [][]string, cols []int) [][]string {
+	rows := make([][]string, 0)
+	for _, h := range head {
+		rows = append(rows, yousikiRec(h, cols))
+	}
+	for _, t := range y.title {
+		r, err1 := strconv.Atoi(t[0])
+		c, err2 := strconv.Atoi(t[1])
+		if err1 == nil && err2 == nil && r < len(rows) && c < len(rows[r]) {
+			rows[r][c] = t[2]
+		}
+	}
+	return rows
+}
+
+// 形式毎に分ける（形式の新しい順）
+func yousikiBunrui(cRecs [][]string) ([]yousiki, map[string][][]string) {
+	bunrui := map[string][][]string{}
+	for _, cRec := range cRecs {
+		y := yousikiFind(cRec)
+		bunrui[y.name] = append(bunrui[y.name], cRec)
+	}
+	ys := make([]yousiki, 0)
+	for _, y := range yousikiList {
+		if _, ok := bunrui[y.name]; ok {
+			ys = append(ys, y)
+		}
+	}
+	if len(ys) == 0 {
+		ys = append(ys, yousikiFind(nil))
+	}
+	sort.SliceStable(ys, func(i, j int) bool { return len(bunrui[ys[i].name]) > len(bunrui[ys[j].name]) })
+	return ys, bunrui
+}
+
+// 形式が複数あるときのファイル名（件数の一番多い形式以外は形式名を付ける）
+func yousikiName(excelName string, y yousiki, i int) string {
+	if i == 0 {
+		return excelName
+	}
+	return strings.TrimSuffix(excelName, ".xlsx") + "（" + y.name + "年形式）.xlsx"
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// 2017年形式の胸部判定アルファベットは二次読影がなければ一次読影の判定
+func TestYousikiHokan(t *testing.T) {
+	head := headRows()[2]
+	y := yousikiList[len(yousikiList)-1]
+	if y.name != "2017" {
+		t.Fatalf("最も古い形式が2017年形式ではありません:%v", y.name)
+	}
+	cols := yousikiCol(y, head)
+
+	tests := []struct {
+		xray1, xray2, want string
+	}{
+		{"A", "", "A"},
+		{"A", "C", "C"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		cRec := make([]string, len(head))
+		cRec[209], cRec[210] = tt.xray1, tt.xray2
+		rec := yousikiHokan(y, head, cRec, yousikiRec(cRec, cols))
+		if rec[201] != tt.want {
+			t.Errorf("胸部判定アルファベット(%q, %q) = %q, want %q", tt.xray1, tt.xray2, rec[201], tt.want)
+		}
+		if rec[200] != tt.xray1 {
+			t.Errorf("胸部X線判定(%q) = %q", tt.xray1, rec[200])
+		}
+	}
+}
+
+// 形式の列は2021年形式の同じ項目名の列（同じ名前の項目は出てくる順に合わせる）
+func TestYousikiCol(t *testing.T) {
+	head := headRows()[2]
+	tests := []struct {
+		name string
+		n    int
+	}{
+		{"2021", 222},
+		{"2018", 221},
+		{"2017", 213},
+	}
+	for _, tt := range tests {
+		var y yousiki
+		for _, yy := range yousikiList {
+			if yy.name == tt.name {
+				y = yy
+			}
+		}
+		cols := yousikiCol(y, head)
+		if len(cols) != tt.n {
+			t.Errorf("%v年形式の列数 = %v, want %v", tt.name, len(cols), tt.n)
+		}
+		used := map[int]bool{}
+		for i, c := range cols {
+			if head[c] != y.koumoku[i] {
+				t.Errorf("%v年形式の%v列目 = %v, want %v", tt.name, i, head[c], y.koumoku[i])
+			}
+			if used[c] {
+				t.Errorf("%v年形式で2021年形式の%v列を2回使っています", tt.name, c)
+			}
+			used[c] = true
+		}
+		if tt.name == "2021" {
+			for i, c := range cols {
+				if i != c {
+					t.Errorf("2021年形式の%v列目 = %v", i, c)
+					break
+				}
+			}
+		}
+	}
+}
+
+// 実施年度で形式を選ぶ（-format の指定がない場合）
+func TestYousikiFind(t *testing.T) {
+	tests := []struct{ nendo, want string }{
+		{"2016", "2017"},
+		{"2017", "2017"},
+		{"2018", "2018"},
+		{"2020", "2018"},
+		{"2021", "2021"},
+		{"2025", "2021"},
+	}
+	for _, tt := range tests {
+		cRec := make([]string, 222)
+		cRec[17] = tt.nendo
+		if got := yousikiFind(cRec); got.name != tt.want {
+			t.Errorf("yousikiFind(実施年度%v) = %v, want %v", tt.nendo, got.name, tt.want)
+		}
+	}
+	if got := yousikiFind(nil); got.name != yousikiList[0].name {
+		t.Errorf("yousikiFind(nil) = %v, want %v", got.name, yousikiList[0].name)
+	}
+}
+
+// 形式にない列の値は誤り。9.社員番号は0.社員番号と同じなら構わない
+func TestYousikiDenai(t *testing.T) {
+	head := headRows()[2]
+	y := yousiki{name: "2017"}
+	denai := map[int]bool{5: true, 9: true}
+	tests := []struct {
+		name string
+		kv   map[int]string
+		want []string
+	}{
+		{"空欄", nil, []string{}},
+		{"形式にある列", map[int]string{6: "1"}, []string{}},
+		{"形式にない列", map[int]string{5: "本人"}, []string{"5." + head[5] + ":2017年形式にない列に値があります"}},
+		{"社員番号が同じ", map[int]string{0: "123", 9: "123"}, []string{}},
+		{"社員番号が違う", map[int]string{0: "123", 9: "124"}, []string{"9." + head[9] + ":2017年形式にない列に値があります"}},
+	}
+	for _, tt := range tests {
+		cRec := make([]string, len(head))
+		for k, v := range tt.kv {
+			cRec[k] = v
+		}
+		if got := yousikiDenai(cRec, head, y, denai); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: yousikiDenai = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}